@@ -0,0 +1,92 @@
+package main
+
+import (
+	"time"
+)
+
+// defaultWeekdayHours is the weekday target when targets.weekday_hours isn't configured.
+const defaultWeekdayHours = 7.5
+
+// Calendar knows which days are expected to be logged (weekdays that aren't holidays) and how many
+// hours are expected of them.
+type Calendar struct {
+	holidays map[string]bool
+	targets  Targets
+}
+
+// NewCalendar builds the calendar from the user configuration's holidays (yyyy-mm-dd days) and
+// targets.
+func NewCalendar(userConf *UserConf) (*Calendar, error) {
+	holidays := make(map[string]bool, len(userConf.Holidays))
+	for _, holiday := range userConf.Holidays {
+		if _, err := time.Parse(time.DateOnly, holiday); err != nil {
+			return nil, WrapWithStackF(err, "holidays = %s: provided day is not in format yyyy-mm-dd. Exiting.", holiday)
+		}
+		holidays[holiday] = true
+	}
+	targets := userConf.Targets
+	if targets.WeekdayHours < 0 || targets.WeekHours < 0 || targets.MonthHours < 0 {
+		return nil, WithStack("targets: hours can't be negative. Exiting.")
+	}
+	if targets.WeekdayHours == 0 {
+		targets.WeekdayHours = defaultWeekdayHours
+	}
+	return &Calendar{holidays: holidays, targets: targets}, nil
+}
+
+// IsHoliday reports whether the day is listed in the configured holidays.
+func (c *Calendar) IsHoliday(day time.Time) bool {
+	return c.holidays[day.Format(time.DateOnly)]
+}
+
+// IsWorkday reports whether the day is a weekday and not a holiday.
+func (c *Calendar) IsWorkday(day time.Time) bool {
+	weekday := day.Weekday()
+	return weekday != time.Saturday && weekday != time.Sunday && !c.IsHoliday(day)
+}
+
+// WeekdayHours is the target of every workday.
+func (c *Calendar) WeekdayHours() float64 {
+	return c.targets.WeekdayHours
+}
+
+// DayTarget is the day's target: the weekday target on workdays, and 0 otherwise.
+func (c *Calendar) DayTarget(day time.Time) float64 {
+	if !c.IsWorkday(day) {
+		return 0
+	}
+	return c.targets.WeekdayHours
+}
+
+// WeekTarget returns the hours expected for the ISO week starting on monday, in total and up to the
+// day (inclusive).
+func (c *Calendar) WeekTarget(monday, day time.Time) (total, toDate float64) {
+	return c.periodTarget(monday, monday.AddDate(0, 0, 6), day, c.targets.WeekHours)
+}
+
+// MonthTarget returns the hours expected for the month starting on first, in total and up to the
+// day (inclusive).
+func (c *Calendar) MonthTarget(first, day time.Time) (total, toDate float64) {
+	return c.periodTarget(first, first.AddDate(0, 1, -1), day, c.targets.MonthHours)
+}
+
+// periodTarget spreads periodHours evenly over the workdays of from..to. When periodHours isn't set,
+// every workday counts for the weekday target.
+func (c *Calendar) periodTarget(from, to, day time.Time, periodHours float64) (total, toDate float64) {
+	var workdays, workdaysToDate int
+	for d := from; !d.After(to); d = d.AddDate(0, 0, 1) {
+		if c.IsWorkday(d) {
+			workdays += 1
+			if !d.After(day) {
+				workdaysToDate += 1
+			}
+		}
+	}
+	if periodHours == 0 {
+		return float64(workdays) * c.targets.WeekdayHours, float64(workdaysToDate) * c.targets.WeekdayHours
+	}
+	if workdays == 0 {
+		return periodHours, 0
+	}
+	return periodHours, periodHours * float64(workdaysToDate) / float64(workdays)
+}
@@ -0,0 +1,497 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-errors/errors"
+	"github.com/urfave/cli/v2"
+)
+
+// LogEntry is a log pulse to create, resolved against the boards configuration.
+type LogEntry struct {
+	Day      string
+	BoardID  int
+	GroupID  string
+	ItemName string
+	Hours    float64
+}
+
+// InputRow is a log entry as read from stdin, before it gets resolved against the boards
+// configuration.
+type InputRow struct {
+	LineNumber  uint
+	DayYYYYMMDD string
+	ItemName    string
+	Hours       string
+}
+
+// LogEntryRecord is the output of create-one and create-many for one log entry.
+// Status is one of created, skipped_duplicate, skipped_resumed or dry_run.
+type LogEntryRecord struct {
+	Line        uint    `json:"line,omitempty"`
+	Status      string  `json:"status"`
+	Day         string  `json:"day"`
+	BoardID     int     `json:"board_id"`
+	GroupID     string  `json:"group_id"`
+	Description string  `json:"description"`
+	Hours       float64 `json:"hours"`
+	PulseID     string  `json:"pulse_id,omitempty"`
+	Link        string  `json:"link,omitempty"`
+	hoursText   string
+}
+
+var logEntryColumns = []Column{
+	{"LINE", "line"},
+	{"", "status"},
+	{"DAY", "day"},
+	{"BOARD ID", "board_id"},
+	{"GROUP ID", "group_id"},
+	{"DESCRIPTION", "description"},
+	{"HOURS", "hours"},
+	{"", "pulse_id"},
+	{"", "link"},
+}
+
+func (r LogEntryRecord) Fields() []any {
+	return []any{r.Line, r.Status, r.Day, r.BoardID, r.GroupID, r.Description, r.hoursText, r.PulseID, r.Link}
+}
+
+func newLogEntryRecord(lineNumber uint, status string, entry *LogEntry) LogEntryRecord {
+	return LogEntryRecord{
+		Line:        lineNumber,
+		Status:      status,
+		Day:         entry.Day,
+		BoardID:     entry.BoardID,
+		GroupID:     entry.GroupID,
+		Description: entry.ItemName,
+		Hours:       entry.Hours,
+		hoursText:   formatHours(entry.Hours),
+	}
+}
+
+func cliCreateOne(cCtx *cli.Context) error {
+	format, err := outputFormat(cCtx)
+	if err != nil {
+		return err
+	}
+	userConf, boardsConf, err := loadConf()
+	if err != nil {
+		return err
+	}
+
+	mondayAPIClient := NewMondayAPIClient(userConf, boardsConf, cCtx.Duration("timeout"))
+
+	var duplicates *DuplicateFinder
+	if !cCtx.Bool("allow-duplicates") {
+		duplicates = NewDuplicateFinder(mondayAPIClient)
+	}
+
+	args := cCtx.Args()
+	day, itemName, hours := args.Get(0), args.Get(1), args.Get(2)
+
+	record, err := createOne(cCtx.Context, mondayAPIClient, duplicates, boardsConf, day, itemName, hours, cCtx.Duration("round"))
+	if err != nil || format == outputTable {
+		return err
+	}
+	return printRecords(format, logEntryColumns, []LogEntryRecord{*record})
+}
+
+// resolveLogEntry maps the day to its board and group through the boards configuration and
+// validates the hours (see parseHours). No call to monday.com is made.
+func resolveLogEntry(boardsConf *BoardsConf, dayYYYYMMDD, itemName, hours string, roundTo time.Duration) (*LogEntry, error) {
+	boardID, groupID, err := resolveDayGroup(boardsConf, dayYYYYMMDD)
+	if err != nil {
+		return nil, err
+	}
+
+	hoursValue, err := parseHours(hours, roundTo)
+	if err != nil {
+		return nil, WrapWithStackF(err, "hours = %s: %s. Exiting.", hours, err.Error())
+	}
+
+	return &LogEntry{
+		Day:      dayYYYYMMDD,
+		BoardID:  boardID,
+		GroupID:  groupID,
+		ItemName: itemName,
+		Hours:    hoursValue,
+	}, nil
+}
+
+// resolveDayGroup maps the yyyy-mm-dd day to its month's board and its day group through the
+// boards configuration.
+func resolveDayGroup(boardsConf *BoardsConf, dayYYYYMMDD string) (int, string, error) {
+	if len(dayYYYYMMDD) != 10 {
+		return 0, "", WithStackF("day = %s: provided day is not in format yyyy-mm-dd. Exiting.", dayYYYYMMDD)
+	}
+
+	monthYYYYMM := dayYYYYMMDD[0:7]
+	if len(boardsConf.Months) == 0 {
+		return 0, "", WithStackF(msgMonthBoardIDNotFound, monthYYYYMM)
+	}
+	month := boardsConf.Months[monthYYYYMM]
+	if month == nil || month.BoardID == "" {
+		return 0, "", WithStackF(msgMonthBoardIDNotFound, monthYYYYMM)
+	}
+	boardIDInt, err := strconv.Atoi(month.BoardID)
+	if err != nil {
+		return 0, "", WrapWithStackF(err, "\"months.%s.board_id\": not a number. Exiting.", monthYYYYMM)
+	}
+
+	dayDD := dayYYYYMMDD[7:10]
+	if len(month.Days) == 0 {
+		return 0, "", WithStackF(msgDayGroupNotFound, monthYYYYMM, dayDD)
+	}
+	dayGroupID := month.Days[dayDD]
+	if dayGroupID == "" {
+		return 0, "", WithStackF(msgDayGroupNotFound, monthYYYYMM, dayDD)
+	}
+	return boardIDInt, dayGroupID, nil
+}
+
+// createOne creates one pulse. The day can be relative (see resolveDay).
+func createOne(ctx context.Context, mondayAPIClient *MondayAPIClient, duplicates *DuplicateFinder, boardsConf *BoardsConf, day, itemName, hours string, roundTo time.Duration) (*LogEntryRecord, error) {
+	dayYYYYMMDD := resolveDay(day, time.Now())
+	entry, err := resolveLogEntry(boardsConf, dayYYYYMMDD, itemName, hours, roundTo)
+	if err != nil {
+		return nil, err
+	}
+	existing, err := duplicates.Find(ctx, entry)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		fmt.Fprintf(infoWriter, "skipped (already exists: pulse %s)\n", existing.ID)
+		record := newLogEntryRecord(0, "skipped_duplicate", entry)
+		record.PulseID, record.Link = existing.ID, mondayAPIClient.PulseLink(existing.Relative_Link)
+		return &record, nil
+	}
+	return createLogEntry(ctx, mondayAPIClient, 0, entry)
+}
+
+// createLogEntry creates the pulse and records it in the history file.
+func createLogEntry(ctx context.Context, mondayAPIClient *MondayAPIClient, lineNumber uint, entry *LogEntry) (*LogEntryRecord, error) {
+	logger.Debugw("CreateLogItem", "day", entry.Day, "boardID", entry.BoardID, "groupID", entry.GroupID, "itemName", entry.ItemName, "hours", entry.Hours)
+
+	res, err := mondayAPIClient.CreateLogItem(ctx, entry.BoardID, entry.GroupID, entry.ItemName, entry.Hours)
+	if err != nil {
+		return nil, err
+	}
+	err = appendHistory(entry, res.Create_Item.ID, res.Create_Item.Relative_Link)
+	if err != nil {
+		// The pulse exists on monday.com at this point, so keep going.
+		fmt.Fprintf(os.Stderr, "Warning: unable to record pulse %s in %s: %v\n", res.Create_Item.ID, historyFilePath, err)
+	}
+	record := newLogEntryRecord(lineNumber, "created", entry)
+	record.PulseID, record.Link = res.Create_Item.ID, mondayAPIClient.PulseLink(res.Create_Item.Relative_Link)
+	fmt.Fprintln(infoWriter, record.Link)
+	return &record, nil
+}
+
+func cliCreateMany(cCtx *cli.Context) error {
+	format, err := outputFormat(cCtx)
+	if err != nil {
+		return err
+	}
+	userConf, boardsConf, err := loadConf()
+	if err != nil {
+		return err
+	}
+
+	mondayAPIClient := NewMondayAPIClient(userConf, boardsConf, cCtx.Duration("timeout"))
+
+	var duplicates *DuplicateFinder
+	if !cCtx.Bool("allow-duplicates") {
+		duplicates = NewDuplicateFinder(mondayAPIClient)
+	}
+
+	opts := CreateManyOptions{
+		Output:  format,
+		Format:  cCtx.String("format"),
+		RoundTo: cCtx.Duration("round"),
+		File:    cCtx.String("file"),
+		DryRun:  cCtx.Bool("dry-run"),
+		Resume:  cCtx.Bool("resume"),
+	}
+	return createMany(cCtx.Context, mondayAPIClient, duplicates, boardsConf, opts)
+}
+
+var (
+	// Example line:
+	// 2006-01-02  My log line  1.50
+	regexRowWithDate = regexp.MustCompile("^([[:digit:]]{4}-[[:digit:]]{2}-[[:digit:]]{2})[[:blank:]]{2,}(.+?)[[:blank:]]{2,}([^[:blank:]h]+)")
+	// Example line:
+	//             My log line  1.50
+	regexRowWithoutDate = regexp.MustCompile("^[[:blank:]]{2,}(.+?)[[:blank:]]{2,}([^[:blank:]h]+)")
+)
+
+// readRegisterRows reads "hledger register -p daily" output. Rows without a date use the date of
+// the closest row above them.
+func readRegisterRows(r io.Reader) ([]InputRow, error) {
+	var rows []InputRow
+	var currentDayYYYYMMDD string
+	var lineNumber uint
+	scanner := bufio.NewScanner(r)
+	scanner.Split(bufio.ScanLines)
+	for scanner.Scan() {
+		lineNumber += 1
+		line := scanner.Text()
+		matches := regexRowWithDate.FindStringSubmatch(line)
+		if len(matches) == 4 {
+			fmt.Fprintf(infoWriter, "line %d: matched row with date: %s, %s, %s\n", lineNumber, matches[1], matches[2], matches[3])
+			currentDayYYYYMMDD = matches[1]
+			rows = append(rows, InputRow{lineNumber, currentDayYYYYMMDD, matches[2], matches[3]})
+			continue
+		}
+		matches = regexRowWithoutDate.FindStringSubmatch(line)
+		if len(matches) == 3 {
+			fmt.Fprintf(infoWriter, "line %d: matched row without date (using %s): %s, %s\n", lineNumber, currentDayYYYYMMDD, matches[1], matches[2])
+			rows = append(rows, InputRow{lineNumber, currentDayYYYYMMDD, matches[1], matches[2]})
+			continue
+		}
+		if line != "" {
+			fmt.Fprintf(infoWriter, "line %d: non-empty line ignored: %s\n", lineNumber, line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, WrapWithStack(err, "scanned stdin lines")
+	}
+	return rows, nil
+}
+
+// ResolvedRow is an input row that passed validation, ready to be created.
+type ResolvedRow struct {
+	LineNumber uint
+	Entry      *LogEntry
+}
+
+// readRows reads stdin rows according to --format.
+func readRows(r io.Reader, opts CreateManyOptions) ([]InputRow, error) {
+	switch opts.Format {
+	case "register":
+		return readRegisterRows(r)
+	case "timedot":
+		return readTimedotRows(r)
+	case "timeclock":
+		return readTimeclockRows(r, opts.RoundTo)
+	case "csv":
+		return readCSVRows(r)
+	case "jsonl":
+		return readJSONLinesRows(r)
+	}
+	return nil, WithStackF("--format = %s: unknown input format. Exiting.", opts.Format)
+}
+
+// readInput returns the content of the file, or of stdin when no file is given.
+func readInput(file string) ([]byte, error) {
+	if file == "" {
+		input, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return nil, WrapWithStack(err, "scanned stdin lines")
+		}
+		return input, nil
+	}
+	input, err := os.ReadFile(file)
+	if err != nil {
+		return nil, WrapWithStackF(err, "--file = %s: unable to read file. Exiting.", file)
+	}
+	return input, nil
+}
+
+type CreateManyOptions struct {
+	// Output is the --output format of the resulting records.
+	Output string
+	// Format is register, timedot, timeclock, csv or jsonl.
+	Format string
+	// File is read instead of stdin when set.
+	File string
+	// RoundTo is the increment hours get rounded to (0 means no rounding).
+	RoundTo time.Duration
+	// DryRun validates and prints the rows without calling monday.com.
+	DryRun bool
+	// Resume skips the lines a previous, interrupted run of the same input already submitted.
+	Resume bool
+}
+
+// createMany works in two phases: every row is resolved and validated first, and pulses only get
+// created when all rows are valid. This avoids leaving a day half-submitted because of a bad line.
+// Submitted lines are tracked in a run file so that an interrupted run can be resumed.
+func createMany(ctx context.Context, mondayAPIClient *MondayAPIClient, duplicates *DuplicateFinder, boardsConf *BoardsConf, opts CreateManyOptions) error {
+	dryRun := opts.DryRun
+	input, err := readInput(opts.File)
+	if err != nil {
+		return err
+	}
+	rows, err := readRows(bytes.NewReader(input), opts)
+	if err != nil {
+		return err
+	}
+
+	resolved, failures := resolveRows(boardsConf, rows, opts.RoundTo)
+	if dryRun {
+		fmt.Fprintln(infoWriter, "Dry run: nothing was sent to monday.com.")
+		records := make([]LogEntryRecord, 0, len(resolved))
+		for _, row := range resolved {
+			records = append(records, newLogEntryRecord(row.LineNumber, "dry_run", row.Entry))
+		}
+		err := printRecords(opts.Output, logEntryColumns, records)
+		if err != nil {
+			return err
+		}
+	}
+	if len(failures) > 0 {
+		fmt.Fprintln(infoWriter, "Failures:")
+		for _, failure := range failures {
+			fmt.Fprintln(infoWriter, failure)
+		}
+		if dryRun {
+			return WithStackF("%d of %d row(s) failed validation. Exiting.", len(failures), len(rows))
+		}
+		return WithStackF("%d of %d row(s) failed validation. Nothing was created. Exiting.", len(failures), len(rows))
+	}
+	if dryRun {
+		return nil
+	}
+
+	runFile, err := OpenRunFile(profileName, input)
+	if err != nil {
+		return err
+	}
+	if runFile.HasProgress() && !opts.Resume {
+		return WithStackF("A previous run of this input stopped before finishing.\n"+
+			"Run again with --resume to only submit the remaining lines, or delete %s to start over. Exiting.", runFile.path)
+	}
+
+	// Table output is the progress printed along the way. Other formats print the records at the
+	// end, including when stopping early, so that scripts know what was created.
+	var records []LogEntryRecord
+	printResult := func() error {
+		if opts.Output == outputTable {
+			return nil
+		}
+		return printRecords(opts.Output, logEntryColumns, records)
+	}
+
+	for i, row := range resolved {
+		if ctx.Err() != nil {
+			printResult()
+			return interruptedError(records, nil, resolved[i:])
+		}
+		if pulseID, ok := runFile.Submitted(row.LineNumber, row.Entry.Day); ok {
+			fmt.Fprintf(infoWriter, "line %d: skipped (already submitted by a previous run: pulse %s)\n", row.LineNumber, pulseID)
+			record := newLogEntryRecord(row.LineNumber, "skipped_resumed", row.Entry)
+			record.PulseID = pulseID
+			records = append(records, record)
+			continue
+		}
+		existing, err := duplicates.Find(ctx, row.Entry)
+		if err != nil && ctx.Err() != nil {
+			printResult()
+			return interruptedError(records, nil, resolved[i:])
+		}
+		if err != nil {
+			printResult()
+			return WrapWithStackF(err, "line %d: %s\n%d of %d row(s) were processed before this failure.%s",
+				row.LineNumber, errorMessage(err), i, len(resolved), msgResumeHint)
+		}
+		var record *LogEntryRecord
+		if existing != nil {
+			fmt.Fprintf(infoWriter, "line %d: skipped (already exists: pulse %s)\n", row.LineNumber, existing.ID)
+			skipped := newLogEntryRecord(row.LineNumber, "skipped_duplicate", row.Entry)
+			skipped.PulseID, skipped.Link = existing.ID, mondayAPIClient.PulseLink(existing.Relative_Link)
+			record = &skipped
+		} else {
+			// Ctrl-C during the duplicate check: the line wasn't submitted.
+			if ctx.Err() != nil {
+				printResult()
+				return interruptedError(records, nil, resolved[i:])
+			}
+			record, err = createLogEntry(ctx, mondayAPIClient, row.LineNumber, row.Entry)
+			if err != nil && ctx.Err() != nil {
+				printResult()
+				return interruptedError(records, &resolved[i], resolved[i+1:])
+			}
+			if err != nil {
+				printResult()
+				return WrapWithStackF(err, "line %d: %s\n%d of %d row(s) were processed before this failure.%s",
+					row.LineNumber, errorMessage(err), i, len(resolved), msgResumeHint)
+			}
+		}
+		records = append(records, *record)
+		err = runFile.Record(row.LineNumber, row.Entry.Day, record.PulseID)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: unable to record line %d in %s: %v\n", row.LineNumber, runFile.path, err)
+		}
+	}
+	err = runFile.Remove()
+	if err != nil {
+		return err
+	}
+	return printResult()
+}
+
+var msgResumeHint = "\nRun the same input again with --resume to only submit the remaining lines."
+
+// interruptedError lists the lines processed before Ctrl-C stopped create-many, the line whose
+// creation failed while stopping (it may or may not have been created), and the lines not submitted.
+func interruptedError(records []LogEntryRecord, unknown *ResolvedRow, notSubmitted []ResolvedRow) error {
+	processed := make([]uint, 0, len(records))
+	for _, record := range records {
+		processed = append(processed, record.Line)
+	}
+	remaining := make([]uint, 0, len(notSubmitted))
+	for _, row := range notSubmitted {
+		remaining = append(remaining, row.LineNumber)
+	}
+	message := "Interrupted.\nCreated or skipped: " + lineList(processed)
+	if unknown != nil {
+		message += fmt.Sprintf("\nUnknown (verify on monday.com whether a pulse was created): line %d", unknown.LineNumber)
+	}
+	message += "\nNot submitted: " + lineList(remaining)
+	return WithStack(message + msgResumeHint)
+}
+
+// lineList formats line numbers for messages.
+func lineList(lineNumbers []uint) string {
+	if len(lineNumbers) == 0 {
+		return "none"
+	}
+	texts := make([]string, len(lineNumbers))
+	for i, lineNumber := range lineNumbers {
+		texts[i] = strconv.FormatUint(uint64(lineNumber), 10)
+	}
+	return "line(s) " + strings.Join(texts, ", ")
+}
+
+// resolveRows resolves every row without contacting monday.com. Rows that fail validation are
+// reported as failure messages naming their line number.
+func resolveRows(boardsConf *BoardsConf, rows []InputRow, roundTo time.Duration) ([]ResolvedRow, []string) {
+	var resolved []ResolvedRow
+	var failures []string
+	for _, row := range rows {
+		entry, err := resolveLogEntry(boardsConf, row.DayYYYYMMDD, row.ItemName, row.Hours, roundTo)
+		if err != nil {
+			failures = append(failures, fmt.Sprintf("line %d: %s", row.LineNumber, errorMessage(err)))
+			continue
+		}
+		resolved = append(resolved, ResolvedRow{LineNumber: row.LineNumber, Entry: entry})
+	}
+	return resolved, failures
+}
+
+// errorMessage returns the simple version of the error message, meant for the command line.
+func errorMessage(err error) string {
+	if cliErr := Messager(nil); errors.As(err, &cliErr) {
+		return cliErr.Message()
+	}
+	return err.Error()
+}
@@ -0,0 +1,198 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Period is an inclusive range of days, as given on the command line.
+type Period struct {
+	From time.Time
+	To   time.Time
+	// WholeMonths is true when the period was given as months (yyyy-mm), in which case every
+	// group of the boards is included, not only the configured days.
+	WholeMonths bool
+}
+
+var regexISOWeek = regexp.MustCompile(`^([[:digit:]]{4})-W([[:digit:]]{2})$`)
+
+// parsePeriod accepts a month (2023-09), a day (2023-09-01), an inclusive range of days or months
+// (2023-09-01..2023-09-15, 2023-08..2023-09) or an ISO week (2023-W36, Monday to Sunday).
+func parsePeriod(arg string) (Period, error) {
+	if from, to, ok := strings.Cut(arg, ".."); ok {
+		fromPeriod, err := parsePeriod(from)
+		if err != nil {
+			return Period{}, err
+		}
+		toPeriod, err := parsePeriod(to)
+		if err != nil {
+			return Period{}, err
+		}
+		if toPeriod.To.Before(fromPeriod.From) {
+			return Period{}, fmt.Errorf("%s ends before it starts", arg)
+		}
+		return Period{
+			From:        fromPeriod.From,
+			To:          toPeriod.To,
+			WholeMonths: fromPeriod.WholeMonths && toPeriod.WholeMonths,
+		}, nil
+	}
+
+	if matches := regexISOWeek.FindStringSubmatch(arg); matches != nil {
+		year, _ := strconv.Atoi(matches[1])
+		week, _ := strconv.Atoi(matches[2])
+		monday := isoWeekMonday(year, week)
+		if week < 1 || week > 53 || isoWeekMonday(year+1, 1).Compare(monday) <= 0 {
+			return Period{}, fmt.Errorf("%s is not a valid ISO week", arg)
+		}
+		return Period{From: monday, To: monday.AddDate(0, 0, 6)}, nil
+	}
+
+	if day, err := time.ParseInLocation(time.DateOnly, arg, time.Local); err == nil {
+		return Period{From: day, To: day}, nil
+	}
+	if month, err := time.ParseInLocation("2006-01", arg, time.Local); err == nil {
+		return Period{From: month, To: month.AddDate(0, 1, -1), WholeMonths: true}, nil
+	}
+	return Period{}, fmt.Errorf("%s is not a month (yyyy-mm), day (yyyy-mm-dd), range (<from>..<to>) or ISO week (yyyy-Www)", arg)
+}
+
+var (
+	// -2, 2d or 2 days ago.
+	regexDaysAgo     = regexp.MustCompile(`^(?:-([[:digit:]]{1,4})|([[:digit:]]{1,4})d|([[:digit:]]{1,4})[[:blank:]]+days?[[:blank:]]+ago)$`)
+	regexLastWeekday = regexp.MustCompile(`^last[[:blank:]]+([[:alpha:]]+)$`)
+)
+
+// resolveDay turns a day argument into yyyy-mm-dd, relative to now in the local timezone. Besides
+// yyyy-mm-dd, it accepts today, yesterday, -N, Nd or "N days ago", a weekday name (mon or monday:
+// the most recent one, today included) and last <weekday> (the most recent one before today).
+func resolveDay(arg string, now time.Time) string {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+	normalized := strings.ToLower(strings.TrimSpace(arg))
+	var day time.Time
+	switch {
+	case normalized == "today":
+		day = today
+	case normalized == "yesterday":
+		day = today.AddDate(0, 0, -1)
+	case regexDaysAgo.MatchString(normalized):
+		matches := regexDaysAgo.FindStringSubmatch(normalized)
+		daysAgo, _ := strconv.Atoi(matches[1] + matches[2] + matches[3])
+		day = today.AddDate(0, 0, -daysAgo)
+	default:
+		name, last := normalized, false
+		if matches := regexLastWeekday.FindStringSubmatch(normalized); matches != nil {
+			name, last = matches[1], true
+		}
+		weekday, ok := parseWeekday(name)
+		if !ok {
+			// Left to the yyyy-mm-dd validation.
+			return arg
+		}
+		daysAgo := (int(today.Weekday()) - int(weekday) + 7) % 7
+		if last && daysAgo == 0 {
+			daysAgo = 7
+		}
+		day = today.AddDate(0, 0, -daysAgo)
+	}
+	return day.Format(time.DateOnly)
+}
+
+// parseWeekday accepts weekday names in full or abbreviated to three letters.
+func parseWeekday(name string) (time.Weekday, bool) {
+	for weekday := time.Sunday; weekday <= time.Saturday; weekday++ {
+		full := strings.ToLower(weekday.String())
+		if name == full || name == full[:3] {
+			return weekday, true
+		}
+	}
+	return 0, false
+}
+
+// isoWeekMonday returns the Monday starting the ISO week. Week 1 is the week containing January 4.
+func isoWeekMonday(year, week int) time.Time {
+	jan4 := time.Date(year, time.January, 4, 0, 0, 0, 0, time.Local)
+	offset := (int(jan4.Weekday()) + 6) % 7 // Days since Monday
+	return jan4.AddDate(0, 0, -offset+(week-1)*7)
+}
+
+// Months returns the yyyy-mm months covered by the period, in order.
+func (p Period) Months() []string {
+	var months []string
+	for m := time.Date(p.From.Year(), p.From.Month(), 1, 0, 0, 0, 0, time.Local); !m.After(p.To); m = m.AddDate(0, 1, 0) {
+		months = append(months, m.Format("2006-01"))
+	}
+	return months
+}
+
+// Contains reports whether the day falls in the period.
+func (p Period) Contains(day time.Time) bool {
+	return !day.Before(p.From) && !day.After(p.To)
+}
+
+// DayIndex maps a board's groups to the day they stand for. It's built from the boards
+// configuration, so it doesn't depend on how groups are titled on monday.com.
+type DayIndex struct {
+	byGroupID map[string]time.Time
+	byTitle   map[string]time.Time
+}
+
+// NewDayIndex indexes the month's configured days ("-dd" keys) by group ID.
+func NewDayIndex(monthYYYYMM string, month *Month) DayIndex {
+	index := DayIndex{
+		byGroupID: make(map[string]time.Time, len(month.Days)),
+		byTitle:   map[string]time.Time{},
+	}
+	for dayDD, groupID := range month.Days {
+		day, err := time.ParseInLocation(time.DateOnly, monthYYYYMM+dayDD, time.Local)
+		if err == nil {
+			index.byGroupID[groupID] = day
+		}
+	}
+	return index
+}
+
+// AddTitles indexes the group titles seen on items whose group ID is known.
+func (d DayIndex) AddTitles(items []BoardItem) {
+	for _, item := range items {
+		if day, ok := d.byGroupID[item.Group.ID]; ok {
+			d.byTitle[item.Group.Title] = day
+		}
+	}
+}
+
+// Lookup returns the day of a group, by ID first and by title otherwise.
+func (d DayIndex) Lookup(groupID, title string) (time.Time, bool) {
+	if day, ok := d.byGroupID[groupID]; ok {
+		return day, true
+	}
+	day, ok := d.byTitle[title]
+	return day, ok
+}
+
+// Days returns the configured days, in chronological order.
+func (d DayIndex) Days() []time.Time {
+	days := make([]time.Time, 0, len(d.byGroupID))
+	for _, day := range d.byGroupID {
+		days = append(days, day)
+	}
+	slices.SortFunc(days, time.Time.Compare)
+	return days
+}
+
+// compareDays orders days chronologically, with unknown days (ok = false) last.
+func compareDays(a time.Time, aOK bool, b time.Time, bOK bool) int {
+	switch {
+	case aOK && bOK:
+		return a.Compare(b)
+	case aOK:
+		return -1
+	case bOK:
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParsePeriod(t *testing.T) {
+	day := func(s string) time.Time {
+		d, err := time.ParseInLocation(time.DateOnly, s, time.Local)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return d
+	}
+	tests := []struct {
+		arg     string
+		want    Period
+		wantErr bool
+	}{
+		{arg: "2023-09", want: Period{From: day("2023-09-01"), To: day("2023-09-30"), WholeMonths: true}},
+		{arg: "2023-09-05", want: Period{From: day("2023-09-05"), To: day("2023-09-05")}},
+		{arg: "2023-09-01..2023-09-15", want: Period{From: day("2023-09-01"), To: day("2023-09-15")}},
+		{arg: "2023-08..2023-09", want: Period{From: day("2023-08-01"), To: day("2023-09-30"), WholeMonths: true}},
+		{arg: "2023-08..2023-09-10", want: Period{From: day("2023-08-01"), To: day("2023-09-10")}},
+		{arg: "2023-W36", want: Period{From: day("2023-09-04"), To: day("2023-09-10")}},
+		{arg: "2020-W53", want: Period{From: day("2020-12-28"), To: day("2021-01-03")}},
+		{arg: "2021-W01", want: Period{From: day("2021-01-04"), To: day("2021-01-10")}},
+		{arg: "2021-W53", wantErr: true},
+		{arg: "2023-W00", wantErr: true},
+		{arg: "2023-09-15..2023-09-01", wantErr: true},
+		{arg: "2023-13", wantErr: true},
+		{arg: "2023-09-31", wantErr: true},
+		{arg: "september", wantErr: true},
+		{arg: "", wantErr: true},
+	}
+	for _, test := range tests {
+		got, err := parsePeriod(test.arg)
+		if test.wantErr {
+			if err == nil {
+				t.Errorf("parsePeriod(%q) = %+v, want an error", test.arg, got)
+			}
+			continue
+		}
+		if err != nil || !got.From.Equal(test.want.From) || !got.To.Equal(test.want.To) || got.WholeMonths != test.want.WholeMonths {
+			t.Errorf("parsePeriod(%q) = %+v, %v, want %+v", test.arg, got, err, test.want)
+		}
+	}
+}
+
+func TestResolveDay(t *testing.T) {
+	// A Wednesday.
+	now := time.Date(2023, time.September, 13, 15, 4, 5, 0, time.Local)
+	tests := map[string]string{
+		"today":        "2023-09-13",
+		"Yesterday":    "2023-09-12",
+		"-1":           "2023-09-12",
+		"-13":          "2023-08-31",
+		"2d":           "2023-09-11",
+		"0d":           "2023-09-13",
+		"1 day ago":    "2023-09-12",
+		"13 days ago":  "2023-08-31",
+		"2 d":          "2 d",
+		"-2d":          "-2d",
+		"wed":          "2023-09-13",
+		"wednesday":    "2023-09-13",
+		"last wed":     "2023-09-06",
+		"mon":          "2023-09-11",
+		"last  monday": "2023-09-11",
+		"thursday":     "2023-09-07",
+		"sun":          "2023-09-10",
+		"2023-09-01":   "2023-09-01",
+		"not a day":    "not a day",
+		"last notaday": "last notaday",
+		"-12345":       "-12345",
+	}
+	for arg, want := range tests {
+		if got := resolveDay(arg, now); got != want {
+			t.Errorf("resolveDay(%q) = %q, want %q", arg, got, want)
+		}
+	}
+}
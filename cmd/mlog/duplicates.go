@@ -0,0 +1,56 @@
+package main
+
+import (
+	"context"
+	"strconv"
+	"strings"
+)
+
+// DuplicateFinder looks for an existing item matching a log entry among the logging user's items.
+// Each board is fetched at most once. A nil *DuplicateFinder finds nothing, which is how
+// --allow-duplicates turns the check off.
+type DuplicateFinder struct {
+	mondayAPIClient *MondayAPIClient
+	itemsByBoardID  map[int][]BoardItem
+}
+
+func NewDuplicateFinder(mondayAPIClient *MondayAPIClient) *DuplicateFinder {
+	return &DuplicateFinder{
+		mondayAPIClient: mondayAPIClient,
+		itemsByBoardID:  map[int][]BoardItem{},
+	}
+}
+
+// Find returns the first existing item in the entry's day group with the same description and
+// hours, or nil when there is none.
+func (d *DuplicateFinder) Find(ctx context.Context, entry *LogEntry) (*BoardItem, error) {
+	if d == nil {
+		return nil, nil
+	}
+
+	items, ok := d.itemsByBoardID[entry.BoardID]
+	if !ok {
+		boardID := strconv.Itoa(entry.BoardID)
+		logger.Debugw("GetBoardItems", "boardID", boardID)
+		board, err := d.mondayAPIClient.GetBoardItems(ctx, boardID, 0)
+		if err != nil {
+			return nil, err
+		}
+		items = board.Items_Page.Items
+		d.itemsByBoardID[entry.BoardID] = items
+	}
+
+	for i, item := range items {
+		if item.Group.ID != entry.GroupID || strings.TrimSpace(item.Name) != strings.TrimSpace(entry.ItemName) {
+			continue
+		}
+		if len(item.Column_Values) == 0 {
+			continue
+		}
+		itemHours, err := strconv.ParseFloat(item.Column_Values[0].Text, 64)
+		if err == nil && formatHours(itemHours) == formatHours(entry.Hours) {
+			return &items[i], nil
+		}
+	}
+	return nil, nil
+}
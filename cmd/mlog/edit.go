@@ -0,0 +1,202 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/urfave/cli/v2"
+)
+
+func cliEdit(cCtx *cli.Context) error {
+	pulseID := cCtx.Args().First()
+	if pulseID == "" {
+		return WithStack("pulse-id (first arg): missing. Exiting.")
+	}
+	if !cCtx.IsSet("name") && !cCtx.IsSet("hours") && !cCtx.IsSet("day") {
+		return WithStack("Nothing to change: use --name, --hours and/or --day. Exiting.")
+	}
+	userConf, boardsConf, err := loadConf()
+	if err != nil {
+		return err
+	}
+
+	mondayAPIClient := NewMondayAPIClient(userConf, boardsConf, cCtx.Duration("timeout"))
+
+	// Validate everything before contacting monday.com.
+	var itemName *string
+	if cCtx.IsSet("name") {
+		name := cCtx.String("name")
+		if strings.TrimSpace(name) == "" {
+			return WithStack("--name: can't be empty. Exiting.")
+		}
+		itemName = &name
+	}
+	var hours *float64
+	if cCtx.IsSet("hours") {
+		value, err := parseHours(cCtx.String("hours"), 0)
+		if err != nil {
+			return WrapWithStackF(err, "--hours = %s: %s. Exiting.", cCtx.String("hours"), err.Error())
+		}
+		hours = &value
+	}
+	var dayYYYYMMDD, groupID string
+	var boardID int
+	if cCtx.IsSet("day") {
+		dayYYYYMMDD = resolveDay(cCtx.String("day"), time.Now())
+		boardID, groupID, err = resolveDayGroup(boardsConf, dayYYYYMMDD)
+		if err != nil {
+			return err
+		}
+	}
+
+	logger.Debugw("GetLogItem", "pulseID", pulseID)
+	item, err := mondayAPIClient.GetLogItem(cCtx.Context, pulseID)
+	if err != nil {
+		return err
+	}
+	err = checkLoggedByUser(cCtx, mondayAPIClient, item)
+	if err != nil {
+		return err
+	}
+	if groupID != "" && strconv.Itoa(boardID) != item.Board.ID {
+		return WithStackF("--day = %s: the day is on board %d, but pulse %s is on board %s. Pulses can only move within their month's board. Exiting.",
+			dayYYYYMMDD, boardID, pulseID, item.Board.ID)
+	}
+
+	// Values equal to the current ones are left out.
+	var changes []string
+	if itemName != nil {
+		if *itemName == item.Name {
+			itemName = nil
+		} else {
+			changes = append(changes, fmt.Sprintf("  description: %s -> %s", item.Name, *itemName))
+		}
+	}
+	currentHours := logItemHours(item)
+	if hours != nil {
+		if current, err := strconv.ParseFloat(currentHours, 64); err == nil && formatHours(current) == formatHours(*hours) {
+			hours = nil
+		} else {
+			changes = append(changes, fmt.Sprintf("  hours: %s -> %s", currentHours, formatHours(*hours)))
+		}
+	}
+	if groupID != "" {
+		if groupID == item.Group.ID {
+			groupID = ""
+		} else {
+			changes = append(changes, fmt.Sprintf("  day: %s -> %s", item.Group.Title, dayYYYYMMDD))
+		}
+	}
+	if len(changes) == 0 {
+		fmt.Printf("Pulse %s already has these values. Nothing to change.\n", pulseID)
+		return nil
+	}
+
+	fmt.Printf("Pulse %s (%s, %s hours, %s):\n%s\n", pulseID, item.Name, currentHours, item.Group.Title, strings.Join(changes, "\n"))
+	if !cCtx.Bool("yes") {
+		ok, err := confirm(os.Stdin, "Apply these changes?")
+		if err != nil || !ok {
+			return err
+		}
+	}
+
+	if itemName != nil || hours != nil {
+		logger.Debugw("UpdateLogItem", "pulseID", pulseID, "boardID", item.Board.ID)
+		err = mondayAPIClient.UpdateLogItem(cCtx.Context, item.Board.ID, pulseID, itemName, hours)
+		if err != nil {
+			return err
+		}
+	}
+	if groupID != "" {
+		logger.Debugw("MoveLogItem", "pulseID", pulseID, "groupID", groupID)
+		err = mondayAPIClient.MoveLogItem(cCtx.Context, pulseID, groupID)
+		if err != nil {
+			return err
+		}
+	}
+	fmt.Println(mondayAPIClient.PulseLink(item.Relative_Link))
+	return nil
+}
+
+func cliDelete(cCtx *cli.Context) error {
+	pulseID := cCtx.Args().First()
+	if pulseID == "" {
+		return WithStack("pulse-id (first arg): missing. Exiting.")
+	}
+	userConf, boardsConf, err := loadConf()
+	if err != nil {
+		return err
+	}
+
+	mondayAPIClient := NewMondayAPIClient(userConf, boardsConf, cCtx.Duration("timeout"))
+
+	logger.Debugw("GetLogItem", "pulseID", pulseID)
+	item, err := mondayAPIClient.GetLogItem(cCtx.Context, pulseID)
+	if err != nil {
+		return err
+	}
+	err = checkLoggedByUser(cCtx, mondayAPIClient, item)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Pulse %s (%s, %s hours, %s) will be deleted.\n", pulseID, item.Name, logItemHours(item), item.Group.Title)
+	if !cCtx.Bool("yes") {
+		ok, err := confirm(os.Stdin, "Delete it?")
+		if err != nil || !ok {
+			return err
+		}
+	}
+
+	logger.Debugw("DeleteLogItem", "pulseID", pulseID)
+	err = mondayAPIClient.DeleteLogItem(cCtx.Context, pulseID)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Deleted pulse %s.\n", pulseID)
+	return nil
+}
+
+// checkLoggedByUser guards against a mistyped pulse ID: a pulse that isn't the logging user's is
+// only changed after confirmation, so --yes refuses it.
+func checkLoggedByUser(cCtx *cli.Context, mondayAPIClient *MondayAPIClient, item *LogItem) error {
+	if mondayAPIClient.IsLoggedByUser(item) {
+		return nil
+	}
+	if cCtx.Bool("yes") {
+		return WithStackF("pulse_id = %s: the pulse isn't logged by you (person column). Run without --yes to confirm. Exiting.", item.ID)
+	}
+	fmt.Printf("⚠️  Pulse %s isn't logged by you (person column).\n", item.ID)
+	return nil
+}
+
+// logItemHours returns the text of the pulse's hours column.
+func logItemHours(item *LogItem) string {
+	if len(item.Column_Values) == 0 {
+		return ""
+	}
+	return item.Column_Values[0].Text
+}
+
+// confirm asks a yes/no question. Anything but y or yes (including no input) is a no.
+func confirm(r io.Reader, question string) (bool, error) {
+	fmt.Printf("%s [y/N] ", question)
+	answer, err := bufio.NewReader(r).ReadString('\n')
+	if err != nil && err != io.EOF {
+		return false, WrapWithStack(err, "Unable to read the answer. Exiting.")
+	}
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return true, nil
+	}
+	if err == io.EOF {
+		fmt.Println()
+	}
+	fmt.Println("Cancelled.")
+	return false, nil
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/urfave/cli/v2"
+)
+
+// GapRecord is the output of gaps: a workday with no pulses (missing) or with fewer hours than
+// the daily target (under).
+type GapRecord struct {
+	Day          string  `json:"day"`
+	Weekday      string  `json:"weekday"`
+	Status       string  `json:"status"`
+	LoggedHours  float64 `json:"logged_hours"`
+	TargetHours  float64 `json:"target_hours"`
+	MissingHours float64 `json:"missing_hours"`
+}
+
+var gapColumns = []Column{
+	{"DAY", "day"},
+	{"WEEKDAY", "weekday"},
+	{"STATUS", "status"},
+	{"LOGGED", "logged_hours"},
+	{"TARGET", "target_hours"},
+	{"MISSING", "missing_hours"},
+}
+
+func (r GapRecord) Fields() []any {
+	return []any{r.Day, r.Weekday, r.Status, r.LoggedHours, r.TargetHours, r.MissingHours}
+}
+
+func cliGaps(cCtx *cli.Context) error {
+	format, err := outputFormat(cCtx)
+	if err != nil {
+		return err
+	}
+	period, err := periodArg(cCtx)
+	if err != nil {
+		return err
+	}
+	userConf, boardsConf, err := loadConf()
+	if err != nil {
+		return err
+	}
+	calendar, err := NewCalendar(userConf)
+	if err != nil {
+		return err
+	}
+	target := calendar.WeekdayHours()
+	if cCtx.IsSet("target") {
+		target = cCtx.Float64("target")
+	}
+
+	// Gaps are found by walking the days of the boards configuration: a month without any would
+	// have none.
+	for _, monthYYYYMM := range period.Months() {
+		if month := boardsConf.Months[monthYYYYMM]; month != nil && len(month.Days) == 0 {
+			return WithStackF(msgMonthDaysNotFound, monthYYYYMM)
+		}
+	}
+
+	mondayAPIClient := NewMondayAPIClient(userConf, boardsConf, cCtx.Duration("timeout"))
+
+	months, err := fetchPeriodItems(cCtx, mondayAPIClient, boardsConf, period, 1)
+	if err != nil {
+		return err
+	}
+
+	loggedByDay, err := hoursByDay(months)
+	if err != nil {
+		return err
+	}
+
+	today := time.Now()
+	var records []GapRecord
+	for _, month := range months {
+		for _, day := range month.Days.Days() {
+			if !period.Contains(day) || day.After(today) || !calendar.IsWorkday(day) {
+				continue
+			}
+			logged, ok := loggedByDay[day]
+			if ok && logged >= target {
+				continue
+			}
+			status := "under"
+			if !ok {
+				status = "missing"
+			}
+			records = append(records, GapRecord{
+				Day:          day.Format(time.DateOnly),
+				Weekday:      day.Weekday().String()[:3],
+				Status:       status,
+				LoggedHours:  roundHundredths(logged),
+				TargetHours:  target,
+				MissingHours: roundHundredths(target - logged),
+			})
+		}
+	}
+
+	if len(records) == 0 {
+		fmt.Fprintln(infoWriter, "No gaps found.")
+		if format == outputTable {
+			return nil
+		}
+	}
+	return printRecords(format, gapColumns, records)
+}
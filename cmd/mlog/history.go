@@ -0,0 +1,183 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"io/fs"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-errors/errors"
+	"github.com/urfave/cli/v2"
+)
+
+// HistoryRecord is one line of the history file, written after every successful CreateLogItem.
+// The file is append-only JSON Lines, stored next to boards.toml, and shared by the profiles.
+type HistoryRecord struct {
+	Timestamp time.Time `json:"timestamp"`
+	// The profile the pulse was created with. Empty for the top-level configuration.
+	Profile      string `json:"profile,omitempty"`
+	Day          string `json:"day"`
+	BoardID      int    `json:"board_id"`
+	GroupID      string `json:"group_id"`
+	ItemName     string `json:"item_name"`
+	Hours        string `json:"hours"`
+	PulseID      string `json:"pulse_id"`
+	RelativeLink string `json:"relative_link"`
+}
+
+func appendHistory(entry *LogEntry, pulseID, relativeLink string) error {
+	record := HistoryRecord{
+		Timestamp:    time.Now(),
+		Profile:      profileName,
+		Day:          entry.Day,
+		BoardID:      entry.BoardID,
+		GroupID:      entry.GroupID,
+		ItemName:     entry.ItemName,
+		Hours:        formatHours(entry.Hours),
+		PulseID:      pulseID,
+		RelativeLink: relativeLink,
+	}
+	line, err := json.Marshal(&record)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(historyFilePath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	_, err = file.Write(append(line, '\n'))
+	if err != nil {
+		return err
+	}
+	return file.Close()
+}
+
+// readHistory returns every record of the history file, oldest first. A missing file means no
+// pulse was created yet.
+func readHistory() ([]HistoryRecord, error) {
+	file, err := os.Open(historyFilePath)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, WrapWithStackF(err, "Unable to open history file %s. Exiting.", historyFilePath)
+	}
+	defer file.Close()
+
+	var records []HistoryRecord
+	var lineNumber uint
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		lineNumber += 1
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+		var record HistoryRecord
+		err := json.Unmarshal(line, &record)
+		if err != nil {
+			return nil, WrapWithStackF(err, "%s line %d: unable to parse history record. Exiting.", historyFilePath, lineNumber)
+		}
+		records = append(records, record)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, WrapWithStackF(err, "Unable to read history file %s. Exiting.", historyFilePath)
+	}
+	return records, nil
+}
+
+// HistoryEntryRecord is the output of history for one pulse.
+type HistoryEntryRecord struct {
+	Created     time.Time `json:"created"`
+	Day         string    `json:"day"`
+	Hours       float64   `json:"hours"`
+	Description string    `json:"description"`
+	PulseID     string    `json:"pulse_id"`
+	Link        string    `json:"link"`
+	hoursText   string
+}
+
+var historyEntryColumns = []Column{
+	{"CREATED", "created"},
+	{"DAY", "day"},
+	{"HOURS", "hours"},
+	{"DESCRIPTION", "description"},
+	{"PULSE ID", "pulse_id"},
+	{"LINK", "link"},
+}
+
+func (r HistoryEntryRecord) Fields() []any {
+	return []any{r.Created.Format(time.DateTime), r.Day, r.hoursText, r.Description, r.PulseID, r.Link}
+}
+
+func cliHistory(cCtx *cli.Context) error {
+	format, err := outputFormat(cCtx)
+	if err != nil {
+		return err
+	}
+	err = loadConfPaths()
+	if err != nil {
+		return err
+	}
+
+	from, to, month := cCtx.String("from"), cCtx.String("to"), cCtx.String("month")
+	if from != "" && len(from) != 10 {
+		return WithStackF("--from = %s: provided day is not in format yyyy-mm-dd. Exiting.", from)
+	}
+	if to != "" && len(to) != 10 {
+		return WithStackF("--to = %s: provided day is not in format yyyy-mm-dd. Exiting.", to)
+	}
+	if month != "" && len(month) != 7 {
+		return WithStackF("--month = %s: provided month is not in format yyyy-mm. Exiting.", month)
+	}
+	search := strings.ToLower(cCtx.String("search"))
+
+	records, err := readHistory()
+	if err != nil {
+		return err
+	}
+	// The history doesn't need credentials, only the web URL for the selected profile's links.
+	var fileConf UserConf
+	_ = loadTOML(userConfFilePath, &fileConf)
+	userConf, err := fileConf.withProfile(profileName)
+	if err != nil {
+		return err
+	}
+	userConf.resolveEndpoints()
+
+	var entries []HistoryEntryRecord
+	for _, record := range records {
+		if record.Profile != profileName {
+			continue
+		}
+		// Days are yyyy-mm-dd, so string comparison is chronological.
+		if from != "" && record.Day < from {
+			continue
+		}
+		if to != "" && record.Day > to {
+			continue
+		}
+		if month != "" && !strings.HasPrefix(record.Day, month+"-") {
+			continue
+		}
+		if search != "" && !strings.Contains(strings.ToLower(record.ItemName), search) {
+			continue
+		}
+		// Written with formatHours, so always a number.
+		hours, _ := strconv.ParseFloat(record.Hours, 64)
+		entries = append(entries, HistoryEntryRecord{
+			Created:     record.Timestamp.Local(),
+			Day:         record.Day,
+			Hours:       hours,
+			Description: record.ItemName,
+			PulseID:     record.PulseID,
+			Link:        userConf.WebURL + record.RelativeLink,
+			hoursText:   record.Hours,
+		})
+	}
+	return printRecords(format, historyEntryColumns, entries)
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxHours is the most hours one log entry can hold.
+const maxHours = 24
+
+var (
+	// Example quantities: 1.5, .5 (no sign, no exponent)
+	regexHoursDecimal = regexp.MustCompile(`^(?:[[:digit:]]+(?:\.[[:digit:]]*)?|\.[[:digit:]]+)$`)
+	// Example quantities: 1h30m, 1.5h, 90m
+	regexHoursUnits = regexp.MustCompile(`^(?:([[:digit:]]+(?:\.[[:digit:]]+)?|\.[[:digit:]]+)h)?(?:([[:digit:]]+(?:\.[[:digit:]]+)?|\.[[:digit:]]+)m)?$`)
+	// Example quantity: 1:30
+	regexHoursClock = regexp.MustCompile(`^([[:digit:]]+):([0-5][[:digit:]])$`)
+)
+
+// hoursQuantity reads a quantity of hours as decimal hours (1.5), hours and/or minutes (1h30m,
+// 1.5h, 90m), hours and minutes (1:30) or timedot dots (each dot is a quarter hour, spaces are
+// ignored). Signs, exponents, NaN and infinities are rejected.
+func hoursQuantity(text string) (float64, error) {
+	text = strings.TrimSpace(text)
+	if text != "" && strings.Trim(text, ". ") == "" {
+		return float64(strings.Count(text, ".")) * 0.25, nil
+	}
+	if regexHoursDecimal.MatchString(text) {
+		return strconv.ParseFloat(text, 64)
+	}
+	if matches := regexHoursClock.FindStringSubmatch(text); matches != nil {
+		hours, _ := strconv.ParseFloat(matches[1], 64)
+		minutes, _ := strconv.ParseFloat(matches[2], 64)
+		return hours + minutes/60, nil
+	}
+	if matches := regexHoursUnits.FindStringSubmatch(text); matches != nil && text != "" {
+		var hours, minutes float64
+		if matches[1] != "" {
+			hours, _ = strconv.ParseFloat(matches[1], 64)
+		}
+		if matches[2] != "" {
+			minutes, _ = strconv.ParseFloat(matches[2], 64)
+		}
+		return hours + minutes/60, nil
+	}
+	return 0, fmt.Errorf("expected decimal hours, 1h30m, 1:30, 90m or dots")
+}
+
+// parseHours reads the hours of a log entry (see hoursQuantity), rounds them to the nearest multiple
+// of roundTo when it's positive, and keeps two decimals. The result must be more than 0 and at most
+// 24 hours.
+func parseHours(text string, roundTo time.Duration) (float64, error) {
+	hours, err := hoursQuantity(text)
+	if err != nil {
+		return 0, err
+	}
+	if roundTo > 0 {
+		hours = time.Duration(hours * float64(time.Hour)).Round(roundTo).Hours()
+	}
+	hours = math.Round(hours*100) / 100
+	if hours <= 0 || hours > maxHours {
+		return 0, fmt.Errorf("%s hours, expected more than 0 and at most %d", formatHours(hours), maxHours)
+	}
+	return hours, nil
+}
+
+// formatHours prints hours with at most two decimals and no trailing zeros.
+func formatHours(hours float64) string {
+	return strconv.FormatFloat(math.Round(hours*100)/100, 'f', -1, 64)
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestHoursQuantity(t *testing.T) {
+	tests := []struct {
+		text    string
+		want    float64
+		wantErr bool
+	}{
+		{text: "1.5", want: 1.5},
+		{text: ".5", want: 0.5},
+		{text: "2.", want: 2},
+		{text: " 3 ", want: 3},
+		{text: "1h30m", want: 1.5},
+		{text: "1.5h", want: 1.5},
+		{text: "90m", want: 1.5},
+		{text: "1:30", want: 1.5},
+		{text: "24:00", want: 24},
+		{text: "....", want: 1},
+		{text: ".. ..", want: 1},
+		{text: "", wantErr: true},
+		{text: "1e2", wantErr: true},
+		{text: "NaN", wantErr: true},
+		{text: "Inf", wantErr: true},
+		{text: "-1", wantErr: true},
+		{text: "+1", wantErr: true},
+		{text: "0:75", wantErr: true},
+		{text: "1:5", wantErr: true},
+		{text: "h", wantErr: true},
+		{text: "1m30h", wantErr: true},
+	}
+	for _, test := range tests {
+		got, err := hoursQuantity(test.text)
+		if test.wantErr {
+			if err == nil {
+				t.Errorf("hoursQuantity(%q) = %v, want an error", test.text, got)
+			}
+			continue
+		}
+		if err != nil || got != test.want {
+			t.Errorf("hoursQuantity(%q) = %v, %v, want %v", test.text, got, err, test.want)
+		}
+	}
+}
+
+func TestParseHours(t *testing.T) {
+	tests := []struct {
+		text    string
+		roundTo time.Duration
+		want    float64
+		wantErr bool
+	}{
+		{text: "1.333", want: 1.33},
+		{text: "24", want: 24},
+		{text: "24:00", want: 24},
+		{text: "0.004", wantErr: true},
+		{text: "0", wantErr: true},
+		{text: "24.01", wantErr: true},
+		{text: "1e2", wantErr: true},
+		{text: "NaN", wantErr: true},
+		{text: "0:75", wantErr: true},
+		{text: "1:05", roundTo: 15 * time.Minute, want: 1},
+		{text: "1:08", roundTo: 15 * time.Minute, want: 1.25},
+		{text: "5m", roundTo: 15 * time.Minute, wantErr: true},
+	}
+	for _, test := range tests {
+		got, err := parseHours(test.text, test.roundTo)
+		if test.wantErr {
+			if err == nil {
+				t.Errorf("parseHours(%q, %v) = %v, want an error", test.text, test.roundTo, got)
+			}
+			continue
+		}
+		if err != nil || got != test.want {
+			t.Errorf("parseHours(%q, %v) = %v, %v, want %v", test.text, test.roundTo, got, err, test.want)
+		}
+	}
+}
+
+func TestFormatHours(t *testing.T) {
+	tests := map[float64]string{
+		1:                 "1",
+		1.5:               "1.5",
+		12.99999999999997: "13",
+		0.333:             "0.33",
+	}
+	for hours, want := range tests {
+		if got := formatHours(hours); got != want {
+			t.Errorf("formatHours(%v) = %q, want %q", hours, got, want)
+		}
+	}
+}
@@ -0,0 +1,331 @@
+package main
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/urfave/cli/v2"
+)
+
+// BoardItemRecord is the output of get-board-items.
+type BoardItemRecord struct {
+	Group       string   `json:"group"`
+	Day         string   `json:"day"`
+	Hours       *float64 `json:"hours"`
+	Description string   `json:"description"`
+	PulseID     string   `json:"pulse_id"`
+	Link        string   `json:"link"`
+	hoursText   string
+}
+
+var boardItemColumns = []Column{
+	{"GROUP", "group"},
+	{"", "day"},
+	{"HOURS", "hours"},
+	{"DESCRIPTION", "description"},
+	{"PULSE ID", "pulse_id"},
+	{"", "link"},
+}
+
+func (r BoardItemRecord) Fields() []any {
+	return []any{r.Group, r.Day, r.hoursText, r.Description, r.PulseID, r.Link}
+}
+
+// newBoardItemRecord forms the record of an item. Hours are null in JSON output when the hours
+// column isn't a number.
+func newBoardItemRecord(mondayAPIClient *MondayAPIClient, item BoardItem, day time.Time, dayOK bool) BoardItemRecord {
+	record := BoardItemRecord{
+		Group:       item.Group.Title,
+		Description: item.Name,
+		PulseID:     item.ID,
+		Link:        mondayAPIClient.PulseLink(item.Relative_Link),
+	}
+	if dayOK {
+		record.Day = day.Format(time.DateOnly)
+	}
+	if len(item.Column_Values) > 0 {
+		record.hoursText = item.Column_Values[0].Text
+		if hours, err := strconv.ParseFloat(record.hoursText, 64); err == nil {
+			record.Hours = &hours
+		}
+	}
+	return record
+}
+
+// MonthItems are the logging user's items on one month's board, restricted to the days of the
+// requested period.
+type MonthItems struct {
+	MonthYYYYMM string
+	Days        DayIndex
+	Items       []BoardItem
+}
+
+// fetchPeriodItems gets the logging user's items for every month the period covers, one board per
+// month. Unless the period is made of whole months, only the items of groups mapped to days of the
+// period (through the boards configuration) are kept.
+//
+// Boards are fetched concurrently by up to `workers` goroutines. Results are in month order.
+func fetchPeriodItems(cCtx *cli.Context, mondayAPIClient *MondayAPIClient, boardsConf *BoardsConf, period Period, workers int) ([]MonthItems, error) {
+	months := period.Months()
+	// Validate the configuration of every month before contacting monday.com.
+	for _, monthYYYYMM := range months {
+		month := boardsConf.Months[monthYYYYMM]
+		if month == nil || month.BoardID == "" {
+			return nil, WithStackF(msgMonthBoardIDNotFound, monthYYYYMM)
+		}
+		if !period.WholeMonths && len(month.Days) == 0 {
+			return nil, WithStackF(msgMonthDaysNotFound, monthYYYYMM)
+		}
+	}
+	if workers < 1 {
+		workers = 1
+	}
+
+	result := make([]MonthItems, len(months))
+	errs := make([]error, len(months))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				result[i], errs[i] = fetchMonthItems(cCtx, mondayAPIClient, boardsConf, period, months[i])
+			}
+		}()
+	}
+	for i := range months {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+func fetchMonthItems(cCtx *cli.Context, mondayAPIClient *MondayAPIClient, boardsConf *BoardsConf, period Period, monthYYYYMM string) (MonthItems, error) {
+	month := boardsConf.Months[monthYYYYMM]
+	days := NewDayIndex(monthYYYYMM, month)
+
+	logger.Debugw("GetBoardItems", "boardID", month.BoardID)
+	boardWithItems, err := mondayAPIClient.GetBoardItems(cCtx.Context, month.BoardID, cCtx.Int("max-items"))
+	if err != nil {
+		return MonthItems{}, err
+	}
+	warnIfCapped(cCtx, boardWithItems)
+
+	items := boardWithItems.Items_Page.Items
+	days.AddTitles(items)
+	if !period.WholeMonths {
+		items = slices.DeleteFunc(items, func(item BoardItem) bool {
+			day, ok := days.Lookup(item.Group.ID, item.Group.Title)
+			return !ok || !period.Contains(day)
+		})
+	}
+	return MonthItems{MonthYYYYMM: monthYYYYMM, Days: days, Items: items}, nil
+}
+
+// warnIfCapped lets the user know when --max-items cut the board's items short.
+func warnIfCapped(cCtx *cli.Context, board *BoardWithItems) {
+	if board.Items_Page.Cursor != "" {
+		fmt.Fprintf(cCtx.App.ErrWriter, "Results capped at %d items (--max-items). More items exist on the board.\n",
+			len(board.Items_Page.Items))
+	}
+}
+
+// periodArg parses the command's first argument as a Period.
+func periodArg(cCtx *cli.Context) (Period, error) {
+	arg := cCtx.Args().First()
+	period, err := parsePeriod(arg)
+	if err != nil {
+		return Period{}, WrapWithStackF(err, "%s. Exiting.", err.Error())
+	}
+	return period, nil
+}
+
+func cliGetBoardItems(cCtx *cli.Context) error {
+	format, err := outputFormat(cCtx)
+	if err != nil {
+		return err
+	}
+	period, err := periodArg(cCtx)
+	if err != nil {
+		return err
+	}
+	userConf, boardsConf, err := loadConf()
+	if err != nil {
+		return err
+	}
+
+	mondayAPIClient := NewMondayAPIClient(userConf, boardsConf, cCtx.Duration("timeout"))
+
+	months, err := fetchPeriodItems(cCtx, mondayAPIClient, boardsConf, period, 1)
+	if err != nil {
+		return err
+	}
+
+	type datedItem struct {
+		item  BoardItem
+		day   time.Time
+		dayOK bool
+	}
+	var items []datedItem
+	for _, month := range months {
+		for _, item := range month.Items {
+			day, ok := month.Days.Lookup(item.Group.ID, item.Group.Title)
+			items = append(items, datedItem{item, day, ok})
+		}
+	}
+	// Chronological by day, then by group title for groups that aren't days, then by pulse ID.
+	slices.SortFunc(items, func(a, b datedItem) int {
+		if c := compareDays(a.day, a.dayOK, b.day, b.dayOK); c != 0 {
+			return c
+		}
+		if c := cmp.Compare(a.item.Group.Title, b.item.Group.Title); c != 0 {
+			return c
+		}
+		// Pulse IDs are numbers.
+		if c := cmp.Compare(len(a.item.ID), len(b.item.ID)); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.item.ID, b.item.ID)
+	})
+
+	records := make([]BoardItemRecord, 0, len(items))
+	for _, item := range items {
+		records = append(records, newBoardItemRecord(mondayAPIClient, item.item, item.day, item.dayOK))
+	}
+	return printRecords(format, boardItemColumns, records)
+}
+
+// GroupSummaryRecord is the output of get-board-item-summary.
+type GroupSummaryRecord struct {
+	Group      string  `json:"group"`
+	Day        string  `json:"day"`
+	TotalHours float64 `json:"total_hours"`
+	PulseCount int     `json:"pulse_count"`
+}
+
+var groupSummaryColumns = []Column{
+	{"GROUP", "group"},
+	{"", "day"},
+	{"TOTAL HOURS", "total_hours"},
+	{"PULSE COUNT", "pulse_count"},
+}
+
+func (r GroupSummaryRecord) Fields() []any {
+	return []any{r.Group, r.Day, r.TotalHours, r.PulseCount}
+}
+
+func cliGetBoardItemSummary(cCtx *cli.Context) error {
+	format, err := outputFormat(cCtx)
+	if err != nil {
+		return err
+	}
+	period, err := periodArg(cCtx)
+	if err != nil {
+		return err
+	}
+	userConf, boardsConf, err := loadConf()
+	if err != nil {
+		return err
+	}
+
+	mondayAPIClient := NewMondayAPIClient(userConf, boardsConf, cCtx.Duration("timeout"))
+
+	months, err := fetchPeriodItems(cCtx, mondayAPIClient, boardsConf, period, 1)
+	if err != nil {
+		return err
+	}
+
+	type groupKey struct {
+		month   string
+		groupID string
+	}
+	type groupData struct {
+		record GroupSummaryRecord
+		day    time.Time
+		dayOK  bool
+	}
+	groupMap := map[groupKey]*groupData{}
+	for _, month := range months {
+		for _, item := range month.Items {
+			hours, err := itemHours(item)
+			if err != nil {
+				return err
+			}
+			key := groupKey{month.MonthYYYYMM, item.Group.ID}
+			gd := groupMap[key]
+			if gd == nil {
+				gd = &groupData{record: GroupSummaryRecord{Group: item.Group.Title}}
+				gd.day, gd.dayOK = month.Days.Lookup(item.Group.ID, item.Group.Title)
+				if gd.dayOK {
+					gd.record.Day = gd.day.Format(time.DateOnly)
+				}
+				groupMap[key] = gd
+			}
+			gd.record.TotalHours += hours
+			gd.record.PulseCount += 1
+		}
+	}
+	groups := make([]*groupData, 0, len(groupMap))
+	for _, gd := range groupMap {
+		groups = append(groups, gd)
+	}
+	// Chronological by day, then by group title for groups that aren't days.
+	slices.SortFunc(groups, func(a, b *groupData) int {
+		if c := compareDays(a.day, a.dayOK, b.day, b.dayOK); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.record.Group, b.record.Group)
+	})
+
+	records := make([]GroupSummaryRecord, 0, len(groups))
+	for _, gd := range groups {
+		gd.record.TotalHours = roundHundredths(gd.record.TotalHours)
+		records = append(records, gd.record)
+	}
+	return printRecords(format, groupSummaryColumns, records)
+}
+
+// hoursByDay sums the items' hours per day. Items of groups that aren't mapped to a day are left
+// out.
+func hoursByDay(months []MonthItems) (map[time.Time]float64, error) {
+	result := map[time.Time]float64{}
+	for _, month := range months {
+		for _, item := range month.Items {
+			day, ok := month.Days.Lookup(item.Group.ID, item.Group.Title)
+			if !ok {
+				continue
+			}
+			hours, err := itemHours(item)
+			if err != nil {
+				return nil, err
+			}
+			result[day] += hours
+		}
+	}
+	return result, nil
+}
+
+// itemHours returns the item's hours column as a number.
+func itemHours(item BoardItem) (float64, error) {
+	var text string
+	if len(item.Column_Values) > 0 {
+		text = item.Column_Values[0].Text
+	}
+	hours, err := strconv.ParseFloat(text, 64)
+	if err != nil {
+		return 0, WrapWithStackF(err, "hours = %s (pulse_id = %s): not a number. Exiting.", text, item.ID)
+	}
+	return hours, nil
+}
@@ -1,21 +1,23 @@
 package main
 
 import (
-	"bufio"
-	"cmp"
+	"context"
 	_ "embed"
 	"fmt"
 	"io"
-	"regexp"
+	"io/fs"
 
 	// "log"
 	"net/http"
 	"os"
+	"os/signal"
+	"path/filepath"
 	"slices"
-	"strconv"
+	"strings"
+	"time"
 
 	"github.com/adrg/xdg"
-	"github.com/cheynewallace/tabby"
+	"github.com/go-errors/errors"
 	"github.com/pelletier/go-toml/v2"
 	"github.com/urfave/cli/v2"
 	"go.uber.org/zap"
@@ -24,12 +26,66 @@ import (
 var (
 	userConfFilePath   string
 	boardsConfFilePath string
+	historyFilePath    string
 	logger             *zap.SugaredLogger
 )
 
 type UserConf struct {
-	APIAccessToken string `toml:"api_access_token"`
-	LoggingUserID  string `toml:"logging_user_id"`
+	// Plaintext. See apiAccessToken for the other sources of the access token, tried first.
+	APIAccessToken        string   `toml:"api_access_token,omitempty"`
+	APIAccessTokenCommand string   `toml:"api_access_token_command,omitempty"`
+	LoggingUserID         string   `toml:"logging_user_id"`
+	Holidays              []string `toml:"holidays,omitempty"`
+	Targets               Targets  `toml:"targets,omitempty"`
+	// Optional, for other monday.com accounts or a mock server. The MLOG_API_URL, MLOG_API_VERSION
+	// and MLOG_WEB_URL environment variables take precedence.
+	APIURL     string `toml:"api_url,omitempty"`
+	APIVersion string `toml:"api_version,omitempty"`
+	WebURL     string `toml:"web_url,omitempty"`
+	// Selected with --profile or MLOG_PROFILE.
+	Profiles map[string]*Profile `toml:"profiles,omitempty"`
+	// The profile the credentials come from (see withProfile), which names their keyring entry.
+	profile string
+}
+
+// defaultBoardsURL is where mlog update fetches boards.toml from.
+const defaultBoardsURL = "https://denis-engcom.github.io/mlog/boards.toml"
+
+const (
+	defaultAPIURL = "https://api.monday.com/v2/"
+	// The latest version of the Monday API won't be used by default until January 2024.
+	defaultAPIVersion = "2023-10"
+	defaultWebURL     = "https://magicboard.monday.com"
+)
+
+// resolveEndpoints applies the environment variable overrides and defaults of the API URL, API
+// version and web URL.
+func (c *UserConf) resolveEndpoints() {
+	for _, endpoint := range []struct {
+		value        *string
+		envVar       string
+		defaultValue string
+	}{
+		{&c.APIURL, "MLOG_API_URL", defaultAPIURL},
+		{&c.APIVersion, "MLOG_API_VERSION", defaultAPIVersion},
+		{&c.WebURL, "MLOG_WEB_URL", defaultWebURL},
+	} {
+		if value := os.Getenv(endpoint.envVar); value != "" {
+			*endpoint.value = value
+		}
+		if *endpoint.value == "" {
+			*endpoint.value = endpoint.defaultValue
+		}
+	}
+	c.WebURL = strings.TrimSuffix(c.WebURL, "/")
+}
+
+// Targets are the hours the user is expected to log. Zero means not set: the week and month
+// targets then add up the weekday target over their workdays.
+type Targets struct {
+	WeekdayHours float64 `toml:"weekday_hours,omitempty"`
+	WeekHours    float64 `toml:"week_hours,omitempty"`
+	MonthHours   float64 `toml:"month_hours,omitempty"`
 }
 
 type BoardsConf struct {
@@ -64,12 +120,43 @@ func main() {
 		EnableBashCompletion: true,
 		Flags: []cli.Flag{
 			&cli.BoolFlag{Name: "debug", Aliases: []string{"d"}},
+			&cli.StringFlag{
+				Name:    "output",
+				Aliases: []string{"o"},
+				Value:   outputTable,
+				Usage:   "output `format` of read and create commands: table, json, csv or tsv",
+			},
+			&cli.DurationFlag{
+				Name:  "timeout",
+				Value: time.Minute,
+				Usage: "give up on a request to monday.com after this `duration`, retries included (0 means no timeout)",
+			},
+			&cli.StringFlag{
+				Name:    "profile",
+				Aliases: []string{"p"},
+				EnvVars: []string{"MLOG_PROFILE"},
+				Usage:   "use the `name`d profile of config.toml ([profiles.<name>]) instead of the top-level settings",
+			},
+		},
+		Before: func(cCtx *cli.Context) error {
+			profileName = cCtx.String("profile")
+			return nil
 		},
 		Commands: cli.Commands{
 			{
 				Name:        "setup",
-				Description: "Setup configuration files needed by the other mlog commands",
-				Action:      cliSetup,
+				Description: "Setup configuration files needed by the other mlog commands. Asks for the access token when needed",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:  "token",
+						Usage: "configure this access `token` without prompting (for scripted setups)",
+					},
+					&cli.BoolFlag{
+						Name:  "keyring",
+						Usage: "store the access token in the OS keyring instead of config.toml",
+					},
+				},
+				Action: cliSetup,
 			},
 			{
 				Name:        "update",
@@ -80,30 +167,117 @@ func main() {
 			{
 				Name:        "get-board-items",
 				Aliases:     []string{"gbi"},
-				ArgsUsage:   "<yyyy-mm>",
-				Description: "Get the logging user's items from the given month's board",
+				ArgsUsage:   "<yyyy-mm | yyyy-mm-dd | <from>..<to> | yyyy-Www>",
+				Description: "Get the logging user's items for the given month, day, range of days or ISO week",
+				Flags:       []cli.Flag{maxItemsFlag},
 				Action:      cliGetBoardItems,
 			},
 			{
 				Name:        "get-board-item-summary",
 				Aliases:     []string{"gbis"},
-				ArgsUsage:   "<yyyy-mm>",
-				Description: "Get the logging user's item summary from the given month's board",
+				ArgsUsage:   "<yyyy-mm | yyyy-mm-dd | <from>..<to> | yyyy-Www>",
+				Description: "Get the logging user's item summary for the given month, day, range of days or ISO week",
+				Flags:       []cli.Flag{maxItemsFlag},
 				Action:      cliGetBoardItemSummary,
 			},
+			{
+				Name:        "report",
+				Aliases:     []string{"r"},
+				Description: "Get per-month, per-week and overall totals of the logging user's items across several months' boards",
+				Flags: []cli.Flag{
+					&cli.StringFlag{Name: "from", Required: true, Usage: "first month of the report, `yyyy-mm`"},
+					&cli.StringFlag{Name: "to", Usage: "last month of the report, `yyyy-mm` (defaults to --from)"},
+					&cli.IntFlag{Name: "workers", Value: 4, Usage: "number of boards fetched concurrently"},
+					maxItemsFlag,
+				},
+				Action: cliReport,
+			},
+			{
+				Name:        "gaps",
+				Aliases:     []string{"g"},
+				ArgsUsage:   "<yyyy-mm | yyyy-mm-dd | <from>..<to> | yyyy-Www>",
+				Description: "List workdays without pulses or below the daily target (weekends, holidays and future days are skipped)",
+				Flags: []cli.Flag{
+					&cli.Float64Flag{Name: "target", Usage: "daily target in `hours` (default: targets.weekday_hours from config.toml, or 7.5)"},
+					maxItemsFlag,
+				},
+				Action: cliGaps,
+			},
+			{
+				Name:        "status",
+				Aliases:     []string{"s"},
+				Description: "Show today's, this week's and this month's logged hours against the targets",
+				Flags:       []cli.Flag{maxItemsFlag},
+				Action:      cliStatus,
+			},
 			{
 				Name:        "create-one",
 				Aliases:     []string{"co"},
-				ArgsUsage:   "<yyyy-mm-dd> <item-description> <hours>",
-				Description: "Create one log entry with info provided on the command line",
+				ArgsUsage:   "<yyyy-mm-dd | today | yesterday | Nd | \"N days ago\" | -N | <weekday> | \"last <weekday>\"> <item-description> <hours>",
+				Description: "Create one log entry with info provided on the command line. A -N day is read as a flag unless -- comes first (mlog co -- -2 ...): use Nd instead (mlog co 2d ...)",
+				Flags:       []cli.Flag{roundFlag, allowDuplicatesFlag},
 				Action:      cliCreateOne,
 			},
 			{
 				Name:        "create-many",
 				Aliases:     []string{"cm"},
-				ArgsUsage:   "<stdin>",
-				Description: "Create log entries based on timeclock/timedot fed to hledger register -p daily",
-				Action:      cliCreateMany,
+				ArgsUsage:   "<stdin or --file>",
+				Description: "Create log entries based on timeclock/timedot fed to hledger register -p daily, or read from a timedot, timeclock, CSV or JSON Lines file",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:  "format",
+						Value: "register",
+						Usage: "input format: register (hledger register -p daily output), timedot, timeclock, csv (date,description,hours) or jsonl",
+					},
+					&cli.StringFlag{
+						Name:  "file",
+						Usage: "read input from `path` instead of stdin",
+					},
+					roundFlag,
+					&cli.BoolFlag{
+						Name:  "dry-run",
+						Usage: "validate every line and print what would be created, without calling monday.com",
+					},
+					allowDuplicatesFlag,
+					&cli.BoolFlag{
+						Name:  "resume",
+						Usage: "skip the lines already submitted by a previous, interrupted run of the same input",
+					},
+				},
+				Action: cliCreateMany,
+			},
+			{
+				Name:        "edit",
+				Aliases:     []string{"e"},
+				ArgsUsage:   "<pulse-id>",
+				Description: "Change the description, hours and/or day of an existing pulse",
+				Flags: []cli.Flag{
+					&cli.StringFlag{Name: "name", Usage: "new `description`"},
+					&cli.StringFlag{Name: "hours", Usage: "new `hours` (decimal hours, 1h30m, 1:30, 90m or dots)"},
+					&cli.StringFlag{Name: "day", Usage: "move the pulse to the group of `yyyy-mm-dd` on the same month's board"},
+					yesFlag,
+				},
+				Action: cliEdit,
+			},
+			{
+				Name:        "delete",
+				Aliases:     []string{"del"},
+				ArgsUsage:   "<pulse-id>",
+				Description: "Delete an existing pulse",
+				Flags:       []cli.Flag{yesFlag},
+				Action:      cliDelete,
+			},
+			{
+				Name:        "history",
+				Aliases:     []string{"h"},
+				Description: "List the pulses created by mlog, as recorded in the local history file",
+				Flags: []cli.Flag{
+					&cli.StringFlag{Name: "from", Usage: "only show pulses for days on or after `yyyy-mm-dd`"},
+					&cli.StringFlag{Name: "to", Usage: "only show pulses for days on or before `yyyy-mm-dd`"},
+					&cli.StringFlag{Name: "month", Usage: "only show pulses for days in `yyyy-mm`"},
+					&cli.StringFlag{Name: "search", Usage: "only show pulses whose description contains `text` (case-insensitive)"},
+				},
+				Action: cliHistory,
 			},
 			{
 				Name:        "pulse-link",
@@ -126,14 +300,44 @@ func main() {
 		ExitErrHandler: customErrorHandler,
 	}
 
-	app.Run(os.Args)
+	// The first Ctrl-C cancels the context so that the command can stop cleanly. From then on, the
+	// default behaviour is restored and another Ctrl-C exits right away.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+	app.RunContext(ctx, os.Args)
+}
+
+var maxItemsFlag = &cli.IntFlag{
+	Name:  "max-items",
+	Usage: "stop fetching pages once this many items are collected (0 means no cap)",
+}
+
+var roundFlag = &cli.DurationFlag{
+	Name:  "round",
+	Usage: "round hours to the nearest `increment` (e.g. 15m). Timeclock durations are rounded per day and description",
+}
+
+var yesFlag = &cli.BoolFlag{
+	Name:    "yes",
+	Aliases: []string{"y"},
+	Usage:   "don't ask for confirmation",
+}
+
+var allowDuplicatesFlag = &cli.BoolFlag{
+	Name:  "allow-duplicates",
+	Usage: "create the pulse even if the day group already has one with the same description and hours",
 }
 
 var (
 	msgMonthBoardIDNotFound    = "\"months.%s.board_id\": not found in boards configuration. Exiting."
+	msgMonthDaysNotFound       = "\"months.%s.days\": not found in boards configuration. Exiting."
 	msgDayGroupNotFound        = "\"month.%s.days.%s\": not found in boards configuration. Exiting."
 	msgUnableToParseUserConf   = "Unable to parse user configuration file.\nRun `mlog setup` for error details."
 	msgUnableToParseBoardsConf = "Unable to parse boards configuration file.\nRun `mlog setup` for error details."
+	msgMissingAPIAccessToken   = "No access token: set MLOG_API_TOKEN, api_access_token_command or api_access_token, or store it in the OS keyring.\nRun `mlog setup` for error details."
 )
 
 func loadConf() (*UserConf, *BoardsConf, error) {
@@ -142,14 +346,36 @@ func loadConf() (*UserConf, *BoardsConf, error) {
 		return nil, nil, err
 	}
 
-	var userConf UserConf
-	err = loadTOML(userConfFilePath, &userConf)
+	var fileConf UserConf
+	err = loadTOML(userConfFilePath, &fileConf)
 	if err != nil {
 		return nil, nil, WrapWithStack(err, msgUnableToParseUserConf)
 	}
-	if userConf.APIAccessToken == "" || userConf.LoggingUserID == "" {
+	err = fileConf.validateProfiles()
+	if err != nil {
+		return nil, nil, err
+	}
+	if profileName == "" && !fileConf.usesTopLevel() {
+		return nil, nil, WithStackF("No profile selected: use --profile or MLOG_PROFILE (profiles: %s). Exiting.", strings.Join(fileConf.profileNames(), ", "))
+	}
+	userConf, err := fileConf.selectProfile()
+	if err != nil {
+		return nil, nil, err
+	}
+	if userConf.LoggingUserID == "" {
 		return nil, nil, WrapWithStack(err, msgUnableToParseUserConf)
 	}
+	token, source, err := userConf.apiAccessToken()
+	if err != nil {
+		return nil, nil, err
+	}
+	if token == "" {
+		return nil, nil, WithStack(msgMissingAPIAccessToken)
+	}
+	logger.Debugw("Access token", "source", source)
+	userConf.APIAccessToken = token
+	userConf.resolveEndpoints()
+	logger.Debugw("Configuration", "profile", profileName, "boards", boardsConfFilePath)
 
 	var boardsConf BoardsConf
 	err = loadTOML(boardsConfFilePath, &boardsConf)
@@ -160,7 +386,7 @@ func loadConf() (*UserConf, *BoardsConf, error) {
 		return nil, nil, WrapWithStack(err, msgUnableToParseBoardsConf)
 	}
 
-	return &userConf, &boardsConf, nil
+	return userConf, &boardsConf, nil
 }
 
 func loadConfPaths() error {
@@ -174,6 +400,11 @@ func loadConfPaths() error {
 	if err != nil {
 		return WrapWithStack(err, "Error: unable to locate boards configuration file. Please send a bug report to the developer. Exiting.")
 	}
+
+	historyFilePath, err = xdg.DataFile("mlog/history.jsonl")
+	if err != nil {
+		return WrapWithStack(err, "Error: unable to locate history file. Please send a bug report to the developer. Exiting.")
+	}
 	return nil
 }
 
@@ -185,17 +416,35 @@ func loadTOML(path string, obj any) error {
 	return toml.NewDecoder(file).Decode(obj)
 }
 
-// TODO Improve setup by
-//  1. asking for access token
-//  2. Calling the "me" API to get the "logging user ID"
-
-//	query {
-//		me {
-//	 		id
-//	 		name
-//	 		url
-//		}
-//	}
+// writeFileAtomic replaces the file with the content: a temporary file is written next to it, then
+// renamed over it, so that the file is never partially written.
+func writeFileAtomic(path string, content []byte, perm fs.FileMode) error {
+	file, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	// Nothing to remove once renamed.
+	defer os.Remove(file.Name())
+	defer file.Close()
+
+	_, err = file.Write(content)
+	if err == nil {
+		err = file.Chmod(perm)
+	}
+	if err == nil {
+		err = file.Sync()
+	}
+	if err == nil {
+		err = file.Close()
+	}
+	if err == nil {
+		err = os.Rename(file.Name(), path)
+	}
+	return err
+}
+
+// cliSetup configures the user (see setupUser) when the user configuration file is missing or
+// incomplete, or when --token or --keyring is given. It then validates the configuration files.
 func cliSetup(cCtx *cli.Context) error {
 	err := loadConfPaths()
 	if err != nil {
@@ -206,24 +455,47 @@ func cliSetup(cCtx *cli.Context) error {
 	fmt.Printf("User configuration path:   %s\n", userConfFilePath)
 	var userConf UserConf
 	err = loadTOML(userConfFilePath, &userConf)
+	// A file that can't be parsed is left for the user to fix rather than overwritten.
+	if err == nil || errors.Is(err, fs.ErrNotExist) {
+		// Setting up a profile that doesn't exist yet adds it.
+		if profileName != "" && userConf.Profiles[profileName] == nil {
+			if userConf.Profiles == nil {
+				userConf.Profiles = map[string]*Profile{}
+			}
+			userConf.Profiles[profileName] = &Profile{}
+		}
+		selected, _ := userConf.withProfile(profileName)
+		var token string
+		token, _, err = selected.apiAccessToken()
+		if err != nil {
+			return err
+		}
+		incomplete := (token == "" || selected.LoggingUserID == "") && (profileName != "" || userConf.usesTopLevel())
+		if cCtx.IsSet("token") || cCtx.Bool("keyring") || incomplete {
+			err = setupUser(cCtx, &userConf, token)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	// Boards configuration files to validate, once each.
+	var boardsFilePaths []string
 	if err != nil {
 		fmt.Println("❌ Unable to parse file (missing or incorrectly formatted)")
 		fmt.Println("❌ Missing api_access_token")
 		fmt.Println("❌ Missing logging_user_id")
 		validConfiguration = false
 	} else {
-		apiAccessToken := userConf.APIAccessToken
-		loggingUserID := userConf.LoggingUserID
-		if apiAccessToken != "" && loggingUserID != "" {
-			fmt.Println("✅ File is valid")
-		} else {
-			if apiAccessToken == "" {
-				fmt.Println("❌ Missing api_access_token")
-				validConfiguration = false
-			}
-			if loggingUserID == "" {
-				fmt.Println("❌ Missing logging_user_id")
-				validConfiguration = false
+		if userConf.usesTopLevel() {
+			validConfiguration = validateCredentials(&userConf, "File") && validConfiguration
+			boardsFilePaths = append(boardsFilePaths, boardsConfFilePath)
+		}
+		for _, name := range userConf.profileNames() {
+			fmt.Printf("Profile %s:\n", name)
+			selected, _ := userConf.withProfile(name)
+			validConfiguration = validateCredentials(selected, "Profile "+name) && validConfiguration
+			if path := userConf.boardsFilePath(name); !slices.Contains(boardsFilePaths, path) {
+				boardsFilePaths = append(boardsFilePaths, path)
 			}
 		}
 	}
@@ -233,361 +505,202 @@ func cliSetup(cCtx *cli.Context) error {
 		return WrapWithStack(err, "The user configuration has one or more validation errors.\nRefer to github.com/denis-engcom/mlog - config.example.toml for how to configure the file properly.")
 	}
 
-	fmt.Printf("Boards configuration path: %s\n", boardsConfFilePath)
-	var boardsConf BoardsConf
-	err = loadTOML(boardsConfFilePath, &boardsConf)
-	if err != nil {
-		fmt.Println("❌ Unable to parse file (missing or incorrectly formatted)")
-		fmt.Println("❌ Missing person_column_id")
-		fmt.Println("❌ Missing hours_column_id")
-		validConfiguration = false
-	} else {
-		personColumnID := boardsConf.PersonColumnID
-		hoursColumnID := boardsConf.HoursColumnID
-		description := boardsConf.Description
-		if personColumnID != "" && hoursColumnID != "" {
-			fmt.Println("✅ File is valid")
-		} else {
-			if personColumnID == "" {
-				fmt.Println("❌ Missing person_column_id")
-				validConfiguration = false
-			}
-			if hoursColumnID == "" {
-				fmt.Println("❌ Missing hours_column_id")
-				validConfiguration = false
-			}
-		}
-		if description != "" {
-			fmt.Println("✅ Description: " + description)
-		}
-		// TODO add summary of data by reusing checks from create-one
+	for _, path := range boardsFilePaths {
+		validConfiguration = validateBoardsConf(path) && validConfiguration
 	}
-
 	if !validConfiguration {
-		return WrapWithStack(err, "The boards configuration has one or more validation errors.\nRun `mlog update` to fetch the latest board configuration.")
+		return WithStack("The boards configuration has one or more validation errors.\nRun `mlog update` to fetch the latest board configuration.")
 	}
 	fmt.Println("Setup complete without errors.")
 	return nil
 }
 
-// TODO Detect when you are already up to date.
-func cliUpdate(cCtx *cli.Context) error {
-	err := loadConfPaths()
-	if err != nil {
-		return err
-	}
-
-	boardsURL := "https://denis-engcom.github.io/mlog/boards.toml"
-	boardsResponse, err := http.Get(boardsURL)
-	if err != nil {
-		return err
-	}
-	defer boardsResponse.Body.Close()
-
-	// Download into a temporary file.
-	// When everything looks good, replace real file at the end as a final step.
-	boardsFile, err := os.Create(boardsConfFilePath + ".tmp")
-	if err != nil {
-		return err
-	}
-	defer boardsFile.Close()
-
-	n, err := io.Copy(boardsFile, boardsResponse.Body)
-	if err != nil {
-		return err
+// validateCredentials prints whether the access token and logging user ID are configured.
+func validateCredentials(userConf *UserConf, label string) bool {
+	apiAccessToken, source, err := userConf.apiAccessToken()
+	loggingUserID := userConf.LoggingUserID
+	if apiAccessToken != "" && loggingUserID != "" {
+		fmt.Printf("✅ %s is valid\n", label)
+		fmt.Printf("✅ Access token from %s\n", source)
+		return true
 	}
-	// Close early to allow the upcoming rename to work.
-	boardsFile.Close()
-
-	err = os.Rename(boardsConfFilePath+".tmp", boardsConfFilePath)
 	if err != nil {
-		return err
+		fmt.Printf("❌ %s\n", err.Error())
+	} else if apiAccessToken == "" {
+		fmt.Println("❌ Missing access token (MLOG_API_TOKEN, api_access_token_command, api_access_token or OS keyring)")
 	}
-	fmt.Printf("GET %s (%d bytes) - successful\n", boardsURL, n)
-	fmt.Printf("Saved to %s\n", boardsConfFilePath)
-
-	var boardsConf BoardsConf
-	err = loadTOML(boardsConfFilePath, &boardsConf)
-	if err == nil && boardsConf.Description != "" {
-		fmt.Println("✅ Description: " + boardsConf.Description)
+	if loggingUserID == "" {
+		fmt.Println("❌ Missing logging_user_id")
 	}
-
-	fmt.Println("Update complete without errors.")
-
-	return nil
+	return false
 }
 
-func cliGetBoardItems(cCtx *cli.Context) error {
-	// TODO Day version of this route
-	// mlog get-board-items 2023-09-01
-	// For now, rely on grepping of group names
-	userConf, boardsConf, err := loadConf()
-	if err != nil {
-		return err
-	}
-
-	mondayAPIClient := NewMondayAPIClient(
-		userConf.APIAccessToken,
-		userConf.LoggingUserID,
-		boardsConf.PersonColumnID,
-		boardsConf.HoursColumnID)
-
-	monthYYYYMM := cCtx.Args().First()
-	month := boardsConf.Months[monthYYYYMM]
-	if month == nil || month.BoardID == "" {
-		return WithStackF(msgMonthBoardIDNotFound, monthYYYYMM)
-	}
-
-	logger.Debugw("GetBoardItems", "boardID", month.BoardID)
-	boardWithItems, err := mondayAPIClient.GetBoardItems(month.BoardID)
+// validateBoardsConf prints whether the boards configuration file has the column IDs.
+func validateBoardsConf(path string) bool {
+	fmt.Printf("Boards configuration path: %s\n", path)
+	var boardsConf BoardsConf
+	err := loadTOML(path, &boardsConf)
 	if err != nil {
-		return err
-	}
-
-	items := boardWithItems.Items_Page.Items
-	slices.SortFunc(items, func(a, b BoardItem) int {
-		aGroup := a.Group.Title
-		bGroup := b.Group.Title
-		if len(aGroup) == 10 && len(bGroup) == 10 {
-			if aGroup[8] > bGroup[8] {
-				return 1
-			} else if aGroup[8] < bGroup[8] {
-				return -1
-			}
-			if aGroup[9] > bGroup[9] {
-				return 1
-			} else if aGroup[9] < bGroup[9] {
-				return -1
-			}
+		fmt.Println("❌ Unable to parse file (missing or incorrectly formatted)")
+		fmt.Println("❌ Missing person_column_id")
+		fmt.Println("❌ Missing hours_column_id")
+		return false
+	}
+	valid := true
+	personColumnID := boardsConf.PersonColumnID
+	hoursColumnID := boardsConf.HoursColumnID
+	description := boardsConf.Description
+	if personColumnID != "" && hoursColumnID != "" {
+		fmt.Println("✅ File is valid")
+	} else {
+		if personColumnID == "" {
+			fmt.Println("❌ Missing person_column_id")
+			valid = false
+		}
+		if hoursColumnID == "" {
+			fmt.Println("❌ Missing hours_column_id")
+			valid = false
 		}
-
-		return cmp.Compare(a.ID, b.ID)
-	})
-
-	//return json.NewEncoder(os.Stdout).Encode(items.Items)
-	table := tabby.New()
-	table.AddHeader("GROUP", "HOURS", "DESCRIPTION", "PULSE ID")
-	for _, item := range boardWithItems.Items_Page.Items {
-		table.AddLine(item.Group.Title, item.Column_Values[0].Text, item.Name, item.ID)
-	}
-	table.Print()
-	return nil
-}
-
-func cliGetBoardItemSummary(cCtx *cli.Context) error {
-	userConf, boardsConf, err := loadConf()
-	if err != nil {
-		return err
 	}
-
-	mondayAPIClient := NewMondayAPIClient(
-		userConf.APIAccessToken,
-		userConf.LoggingUserID,
-		boardsConf.PersonColumnID,
-		boardsConf.HoursColumnID)
-
-	monthYYYYMM := cCtx.Args().First()
-	month := boardsConf.Months[monthYYYYMM]
-	if month == nil || month.BoardID == "" {
-		return WithStackF(msgMonthBoardIDNotFound, monthYYYYMM)
+	if description != "" {
+		fmt.Println("✅ Description: " + description)
 	}
+	// TODO add summary of data by reusing checks from create-one
+	return valid
+}
 
-	logger.Debugw("GetBoardItems", "boardID", month.BoardID)
-	boardWithItems, err := mondayAPIClient.GetBoardItems(month.BoardID)
+func cliUpdate(cCtx *cli.Context) error {
+	err := loadConfPaths()
 	if err != nil {
 		return err
 	}
-
-	type GroupData struct {
-		Group      string
-		TotalHours float64
-		PulseCount int
-	}
-	groupMap := map[string]GroupData{}
-	for _, item := range boardWithItems.Items_Page.Items {
-		hours, err := strconv.ParseFloat(item.Column_Values[0].Text, 64)
+	// A profile can have its own boards configuration file, fetched from its own URL.
+	boardsURL := defaultBoardsURL
+	if profileName != "" {
+		var userConf UserConf
+		err = loadTOML(userConfFilePath, &userConf)
 		if err != nil {
-			return WrapWithStackF(err, "hours = %s (pulse_id = %s): not a number. Exiting.",
-				item.Column_Values[0].Text,
-				item.ID)
+			return WrapWithStack(err, msgUnableToParseUserConf)
 		}
-		gd := groupMap[item.Group.Title]
-		gd.TotalHours += hours
-		gd.PulseCount += 1
-		groupMap[item.Group.Title] = gd
-	}
-	groups := make([]GroupData, 0, len(groupMap))
-	for gKey, gVal := range groupMap {
-		gVal.Group = gKey
-		groups = append(groups, gVal)
-	}
-
-	slices.SortFunc(groups, func(a, b GroupData) int {
-		aGroup := a.Group
-		bGroup := b.Group
-		if len(aGroup) == 10 && len(bGroup) == 10 {
-			if aGroup[8] > bGroup[8] {
-				return 1
-			} else if aGroup[8] < bGroup[8] {
-				return -1
-			}
-			if aGroup[9] > bGroup[9] {
-				return 1
-			} else if aGroup[9] < bGroup[9] {
-				return -1
-			}
+		err = userConf.validateProfiles()
+		if err != nil {
+			return err
+		}
+		_, err = userConf.selectProfile()
+		if err != nil {
+			return err
+		}
+		boardsURL, err = userConf.boardsURL(profileName)
+		if err != nil {
+			return err
 		}
-
-		return cmp.Compare(aGroup, bGroup)
-	})
-
-	table := tabby.New()
-	table.AddHeader("GROUP", "TOTAL HOURS", "PULSE COUNT")
-	for _, group := range groups {
-		table.AddLine(group.Group, group.TotalHours, group.PulseCount)
 	}
-	table.Print()
-	return nil
-}
 
-func cliCreateOne(cCtx *cli.Context) error {
-	userConf, boardsConf, err := loadConf()
+	req, err := http.NewRequestWithContext(cCtx.Context, http.MethodGet, boardsURL, nil)
 	if err != nil {
 		return err
 	}
-
-	mondayAPIClient := NewMondayAPIClient(
-		userConf.APIAccessToken,
-		userConf.LoggingUserID,
-		boardsConf.PersonColumnID,
-		boardsConf.HoursColumnID)
-
-	args := cCtx.Args()
-	dayYYYYMMDD, itemName, hours := args.Get(0), args.Get(1), args.Get(2)
-
-	return createOne(mondayAPIClient, boardsConf, dayYYYYMMDD, itemName, hours)
-}
-
-func createOne(mondayAPIClient *MondayAPIClient, boardsConf *BoardsConf, dayYYYYMMDD, itemName, hours string) error {
-	if len(dayYYYYMMDD) != 10 {
-		return WithStackF("day = %s (first arg): provided day is not in format yyyy-mm-dd. Exiting.", dayYYYYMMDD)
-	}
-
-	monthYYYYMM := dayYYYYMMDD[0:7]
-	if len(boardsConf.Months) == 0 {
-		return WithStackF(msgMonthBoardIDNotFound, monthYYYYMM)
+	// Conditional request: the server answers 304 Not Modified when the file didn't change.
+	meta := readUpdateMeta(boardsConfFilePath, boardsURL)
+	if meta.ETag != "" {
+		req.Header.Set("If-None-Match", meta.ETag)
 	}
-	month := boardsConf.Months[monthYYYYMM]
-	if month == nil || month.BoardID == "" {
-		return WithStackF(msgMonthBoardIDNotFound, monthYYYYMM)
+	if meta.LastModified != "" {
+		req.Header.Set("If-Modified-Since", meta.LastModified)
 	}
-	boardIDInt, err := strconv.Atoi(month.BoardID)
+	httpClient := &http.Client{Timeout: cCtx.Duration("timeout")}
+	boardsResponse, err := httpClient.Do(req)
 	if err != nil {
-		return WrapWithStackF(err, "\"months.%s.board_id\": not a number. Exiting.", monthYYYYMM)
+		return WrapWithStackF(err, "GET %s: %v. Exiting.", boardsURL, err)
 	}
+	defer boardsResponse.Body.Close()
 
-	dayDD := dayYYYYMMDD[7:10]
-	if len(month.Days) == 0 {
-		return WithStackF(msgDayGroupNotFound, monthYYYYMM, dayDD)
-	}
-	dayGroupID := month.Days[dayDD]
-	if dayGroupID == "" {
-		return WithStackF(msgDayGroupNotFound, monthYYYYMM, dayDD)
+	switch {
+	case boardsResponse.StatusCode == http.StatusNotModified:
+		fmt.Printf("GET %s - not modified\n", boardsURL)
+		fmt.Printf("✅ Already up to date: %s\n", boardsConfFilePath)
+		return nil
+	case boardsResponse.StatusCode < 200 || boardsResponse.StatusCode > 299:
+		return WithStackF("GET %s: %s. Kept %s as is. Exiting.", boardsURL, boardsResponse.Status, boardsConfFilePath)
 	}
-	logger.Debugw("CreateLogItem", "day", dayYYYYMMDD, "boardID", boardIDInt, "groupID", dayGroupID, "itemName", itemName, "hours", hours)
 
-	res, err := mondayAPIClient.CreateLogItem(boardIDInt, dayGroupID, itemName, hours)
+	content, err := io.ReadAll(boardsResponse.Body)
 	if err != nil {
-		return err
+		return WrapWithStackF(err, "GET %s: %v. Kept %s as is. Exiting.", boardsURL, err, boardsConfFilePath)
 	}
-	fmt.Printf("https://magicboard.monday.com%s\n", res.Create_Item.Relative_Link)
-	return nil
-}
-
-func cliCreateMany(cCtx *cli.Context) error {
-	userConf, boardsConf, err := loadConf()
+	// When everything looks good, replace real file at the end as a final step.
+	boardsConf, err := parseBoardsConf(content)
+	if err != nil {
+		return WrapWithStackF(err, "GET %s: not a valid boards configuration (%v). Kept %s as is. Exiting.", boardsURL, err, boardsConfFilePath)
+	}
+	fmt.Printf("GET %s (%d bytes) - successful\n", boardsURL, len(content))
+	replaced, backedUp, err := replaceBoardsConf(boardsConfFilePath, content)
 	if err != nil {
 		return err
 	}
+	writeUpdateMeta(boardsConfFilePath, UpdateMeta{
+		URL:          boardsURL,
+		ETag:         boardsResponse.Header.Get("ETag"),
+		LastModified: boardsResponse.Header.Get("Last-Modified"),
+	})
+	if replaced && backedUp {
+		fmt.Printf("Saved to %s (previous file kept as %s.bak)\n", boardsConfFilePath, filepath.Base(boardsConfFilePath))
+	} else if replaced {
+		fmt.Printf("Saved to %s\n", boardsConfFilePath)
+	} else {
+		fmt.Printf("✅ Already up to date: %s\n", boardsConfFilePath)
+	}
+	if boardsConf.Description != "" {
+		fmt.Println("✅ Description: " + boardsConf.Description)
+	}
 
-	mondayAPIClient := NewMondayAPIClient(
-		userConf.APIAccessToken,
-		userConf.LoggingUserID,
-		boardsConf.PersonColumnID,
-		boardsConf.HoursColumnID)
+	fmt.Println("Update complete without errors.")
 
-	return createMany(mondayAPIClient, boardsConf)
+	return nil
 }
 
-var (
-	// Example line:
-	// 2006-01-02  My log line  1.50
-	regexRowWithDate = regexp.MustCompile("^([[:digit:]]{4}-[[:digit:]]{2}-[[:digit:]]{2})[[:blank:]]{2,}(.+?)[[:blank:]]{2,}([^[:blank:]h]+)")
-	// Example line:
-	//             My log line  1.50
-	regexRowWithoutDate = regexp.MustCompile("^[[:blank:]]{2,}(.+?)[[:blank:]]{2,}([^[:blank:]h]+)")
-)
+// PulseLinkRecord is the output of pulse-link.
+type PulseLinkRecord struct {
+	PulseID string `json:"pulse_id"`
+	Link    string `json:"link"`
+}
 
-func createMany(mondayAPIClient *MondayAPIClient, boardsConf *BoardsConf) error {
-	var currentDayYYYYMMDD string
-	var lineNumber uint
-	scanner := bufio.NewScanner(os.Stdin)
-	scanner.Split(bufio.ScanLines)
-	for scanner.Scan() {
-		lineNumber += 1
-		line := scanner.Text()
-		matches := regexRowWithDate.FindStringSubmatch(line)
-		if len(matches) == 4 {
-			fmt.Printf("line %d: matched row with date: %s, %s, %s\n", lineNumber, matches[1], matches[2], matches[3])
-			currentDayYYYYMMDD = matches[1]
-			err := createOne(mondayAPIClient, boardsConf, currentDayYYYYMMDD, matches[2], matches[3])
-			if err != nil {
-				return WrapWithStack(err, "created one from row with date")
-			}
-			continue
-		}
-		matches = regexRowWithoutDate.FindStringSubmatch(line)
-		if len(matches) == 3 {
-			fmt.Printf("line %d: matched row without date (using %s): %s, %s\n", lineNumber, currentDayYYYYMMDD, matches[1], matches[2])
-			err := createOne(mondayAPIClient, boardsConf, currentDayYYYYMMDD, matches[1], matches[2])
-			if err != nil {
-				return WrapWithStack(err, "created one from row with date")
-			}
-			continue
-		}
-		if line != "" {
-			fmt.Printf("line %d: non-empty line ignored: %s\n", lineNumber, line)
-		}
-	}
-	if err := scanner.Err(); err != nil {
-		return WrapWithStack(err, "scanned stdin lines")
-	}
+var pulseLinkColumns = []Column{
+	{"PULSE ID", "pulse_id"},
+	{"LINK", "link"},
+}
 
-	return nil
+func (r PulseLinkRecord) Fields() []any {
+	return []any{r.PulseID, r.Link}
 }
 
 func cliPulseLink(cCtx *cli.Context) error {
+	format, err := outputFormat(cCtx)
+	if err != nil {
+		return err
+	}
 	userConf, boardsConf, err := loadConf()
 	if err != nil {
 		return err
 	}
 
-	mondayAPIClient := NewMondayAPIClient(
-		userConf.APIAccessToken,
-		userConf.LoggingUserID,
-		boardsConf.PersonColumnID,
-		boardsConf.HoursColumnID)
+	mondayAPIClient := NewMondayAPIClient(userConf, boardsConf, cCtx.Duration("timeout"))
 
 	pulseID := cCtx.Args().First()
 
 	logger.Debugw("GetPulseRelativeLink", "pulseID", pulseID)
-	prl, err := mondayAPIClient.GetPulseRelativeLink(pulseID)
+	prl, err := mondayAPIClient.GetPulseRelativeLink(cCtx.Context, pulseID)
 	if err != nil {
 		return err
 	}
 
-	fmt.Printf("https://magicboard.monday.com%s\n", prl.Relative_Link)
-	return nil
+	link := mondayAPIClient.PulseLink(prl.Relative_Link)
+	if format == outputTable {
+		// Plain link, for use like `open $(mlog pulse-link <pulse-id>)`.
+		fmt.Println(link)
+		return nil
+	}
+	return printRecords(format, pulseLinkColumns, []PulseLinkRecord{{PulseID: pulseID, Link: link}})
 }
 
 func cliGetBoardByID(cCtx *cli.Context) error {
@@ -596,18 +709,14 @@ func cliGetBoardByID(cCtx *cli.Context) error {
 		return err
 	}
 
-	mondayAPIClient := NewMondayAPIClient(
-		userConf.APIAccessToken,
-		userConf.LoggingUserID,
-		boardsConf.PersonColumnID,
-		boardsConf.HoursColumnID)
+	mondayAPIClient := NewMondayAPIClient(userConf, boardsConf, cCtx.Duration("timeout"))
 
-	return getBoardByID(mondayAPIClient, cCtx.Args().First())
+	return getBoardByID(cCtx.Context, mondayAPIClient, cCtx.Args().First())
 }
 
-func getBoardByID(mondayAPIClient *MondayAPIClient, boardID string) error {
+func getBoardByID(ctx context.Context, mondayAPIClient *MondayAPIClient, boardID string) error {
 	logger.Debugw("GetBoardByID", "boardID", boardID)
-	board, err := mondayAPIClient.GetBoardByID(boardID)
+	board, err := mondayAPIClient.GetBoardByID(ctx, boardID)
 	if err != nil {
 		return err
 	}
@@ -2,10 +2,10 @@ package main
 
 import (
 	"context"
-	"fmt"
+	"encoding/json"
 	"github.com/hasura/go-graphql-client"
 	"net/http"
-	"strconv"
+	"time"
 )
 
 // JSONEncodedString avoids a type mismatch in the GraphQL library when setting a JSON-encoded string property.
@@ -20,25 +20,29 @@ func (_ CompareValue) GetGraphQLType() string { return "CompareValue" }
 
 type MondayAPIClient struct {
 	client         *graphql.Client
+	webURL         string
 	loggingUserID  string
 	personColumnID string
 	hoursColumnID  string
 }
 
 // NewMondayAPIClient forms the client with common information needed during Monday API calls.
-func NewMondayAPIClient(apiAccessToken, loggingUserID, personColumnID, hoursColumnID string) *MondayAPIClient {
-	client := graphql.NewClient("https://api.monday.com/v2/", nil).
+// The endpoints must have been resolved (see UserConf.resolveEndpoints). Each HTTP request to
+// monday.com is limited to timeout (0 means no limit).
+func NewMondayAPIClient(userConf *UserConf, boardsConf *BoardsConf, timeout time.Duration) *MondayAPIClient {
+	apiAccessToken, apiVersion := userConf.APIAccessToken, userConf.APIVersion
+	client := graphql.NewClient(userConf.APIURL, &retryDoer{doer: http.DefaultClient, timeout: timeout}).
 		//WithDebug(true).
 		WithRequestModifier(func(req *http.Request) {
 			req.Header.Add("Authorization", apiAccessToken)
-			// The latest version of the Monday API won't be used by default until January 2024.
-			req.Header.Add("API-Version", "2023-10")
+			req.Header.Add("API-Version", apiVersion)
 		})
 	return &MondayAPIClient{
 		client:         client,
-		loggingUserID:  loggingUserID,
-		personColumnID: personColumnID,
-		hoursColumnID:  hoursColumnID,
+		webURL:         userConf.WebURL,
+		loggingUserID:  userConf.LoggingUserID,
+		personColumnID: boardsConf.PersonColumnID,
+		hoursColumnID:  boardsConf.HoursColumnID,
 	}
 }
 
@@ -60,12 +64,12 @@ type GetBoardsQuery struct {
 }
 
 // GetBoardByID calls the Monday API "boards" query with a single board and returns it.
-func (m *MondayAPIClient) GetBoardByID(boardID string) (*Board, error) {
+func (m *MondayAPIClient) GetBoardByID(ctx context.Context, boardID string) (*Board, error) {
 	vars := map[string]any{
 		"board_ids": []graphql.ID{graphql.ToID(boardID)},
 	}
 	var gbq GetBoardsQuery
-	err := m.client.Query(context.TODO(), &gbq, vars)
+	err := m.client.Query(ctx, &gbq, vars)
 	if err != nil {
 		return nil, WrapWithStackF(err,
 			"A problem occurred when contacting monday.com. Exiting.")
@@ -82,16 +86,19 @@ func (m *MondayAPIClient) GetBoardByID(boardID string) (*Board, error) {
 //	      items {
 //	        id
 //	        name
-//	        group { title }
+//	        relative_link
+//	        group { id title }
 //	        column_values(ids: "hours-column") { text }
 //	      }
 //	    }
 //	  }
 //	}
 type BoardItem struct {
-	ID    string
-	Name  string
-	Group struct {
+	ID            string
+	Name          string
+	Relative_Link string
+	Group         struct {
+		ID    string
 		Title string
 	}
 	Column_Values []struct {
@@ -99,51 +106,118 @@ type BoardItem struct {
 	} `graphql:"column_values(ids: $hours_column_id)"`
 }
 
+// ItemsPage is one page of items along with the cursor to fetch the next one.
+// An empty cursor means there are no more pages.
+type ItemsPage struct {
+	Cursor string
+	Items  []BoardItem
+}
+
 type BoardWithItems struct {
 	ID         string
 	Name       string
-	Items_Page struct {
-		Cursor string
-		Items  []BoardItem
-	} `graphql:"items_page(limit: 100, query_params: { rules: { column_id: $person_column_id, compare_value: $logging_user_id} })"`
+	Items_Page ItemsPage `graphql:"items_page(limit: $limit, query_params: { rules: { column_id: $person_column_id, compare_value: $logging_user_id} })"`
 }
 
 type GetBoardItemsQuery struct {
 	Boards []BoardWithItems `graphql:"boards(ids: $board_ids)"`
 }
 
+//	query {
+//	  next_items_page(limit: 100, cursor: "MSw1MDY0MjczNDUxLGlfOXZ...") {
+//	    cursor
+//	    items { ... }
+//	  }
+//	}
+type GetNextItemsPageQuery struct {
+	Next_Items_Page ItemsPage `graphql:"next_items_page(limit: $limit, cursor: $cursor)"`
+}
+
+// itemsPageLimit is the number of items requested per page.
+const itemsPageLimit = 100
+
 // GetBoardItems calls the Monday API "boards" query and returns the logging user's items.
-func (m *MondayAPIClient) GetBoardItems(boardID string) (*BoardWithItems, error) {
+// Pages are followed with "next_items_page" until the cursor is exhausted, or until maxItems
+// items have been collected (maxItems <= 0 means no cap). The returned Items_Page contains all
+// collected items, and its cursor is only non-empty when the cap cut the results short.
+func (m *MondayAPIClient) GetBoardItems(ctx context.Context, boardID string, maxItems int) (*BoardWithItems, error) {
 	vars := map[string]any{
 		"board_ids":        []graphql.ID{graphql.ToID(boardID)},
+		"limit":            pageLimit(maxItems, 0),
 		"logging_user_id":  CompareValue("person-" + m.loggingUserID),
 		"hours_column_id":  []string{m.hoursColumnID},
 		"person_column_id": graphql.ToID(m.personColumnID),
 	}
 	var gbiq GetBoardItemsQuery
-	err := m.client.Query(context.TODO(), &gbiq, vars)
+	err := m.client.Query(ctx, &gbiq, vars)
 	if err != nil {
 		return nil, WrapWithStackF(err,
 			"A problem occurred when contacting monday.com. Exiting.")
 	}
-	return &gbiq.Boards[0], nil
+	if len(gbiq.Boards) == 0 {
+		return nil, WithStackF("board_id = %s: board not found on monday.com. Exiting.", boardID)
+	}
+	board := &gbiq.Boards[0]
+
+	for board.Items_Page.Cursor != "" {
+		limit := pageLimit(maxItems, len(board.Items_Page.Items))
+		if limit == 0 {
+			break
+		}
+		vars := map[string]any{
+			"cursor":          board.Items_Page.Cursor,
+			"limit":           limit,
+			"hours_column_id": []string{m.hoursColumnID},
+		}
+		var gnipq GetNextItemsPageQuery
+		err := m.client.Query(ctx, &gnipq, vars)
+		if err != nil {
+			return nil, WrapWithStackF(err,
+				"A problem occurred when contacting monday.com. Exiting.")
+		}
+		logger.Debugw("GetNextItemsPage", "boardID", boardID, "itemCount", len(gnipq.Next_Items_Page.Items))
+		board.Items_Page.Items = append(board.Items_Page.Items, gnipq.Next_Items_Page.Items...)
+		board.Items_Page.Cursor = gnipq.Next_Items_Page.Cursor
+	}
+	return board, nil
+}
+
+// pageLimit returns how many items to request next, given how many were already collected.
+func pageLimit(maxItems, collected int) int {
+	if maxItems <= 0 {
+		return itemsPageLimit
+	}
+	remaining := maxItems - collected
+	if remaining < 0 {
+		return 0
+	}
+	if remaining > itemsPageLimit {
+		return itemsPageLimit
+	}
+	return remaining
 }
 
 type CreateLogItemMutate struct {
 	Create_Item struct {
+		ID            string
 		Relative_Link string
 	} `graphql:"create_item (board_id: $board_id, group_id: $group_id, item_name: $item_name, column_values: $column_values)"`
 }
 
 // CreateLogItem calls the Monday api "create_item" mutation.
-func (m *MondayAPIClient) CreateLogItem(boardID int, groupID, itemName, hours string) (*CreateLogItemMutate, error) {
-	// Validating it's a float, but can still make direct use of the string value in the request.
-	_, err := strconv.ParseFloat(hours, 64)
-	if err != nil {
-		return nil, WrapWithStackF(err, "hours = %s (third arg): unable to parse hours as a number. Exiting.", hours)
+func (m *MondayAPIClient) CreateLogItem(ctx context.Context, boardID int, groupID, itemName string, hours float64) (*CreateLogItemMutate, error) {
+	// Also rejects NaN.
+	if !(hours > 0 && hours <= maxHours) {
+		return nil, WithStackF("hours = %s: expected more than 0 and at most %d hours. Exiting.", formatHours(hours), maxHours)
 	}
 	// Person and Hours key-value pairs have to be provided together as a JSON-encoded string property.
-	columnValues := fmt.Sprintf(`{"%s":"%s","%s":%s}`, m.personColumnID, m.loggingUserID, m.hoursColumnID, hours)
+	columnValues, err := json.Marshal(map[string]any{
+		m.personColumnID: m.loggingUserID,
+		m.hoursColumnID:  hours,
+	})
+	if err != nil {
+		return nil, WrapWithStack(err, "Unable to encode column values. Exiting.")
+	}
 
 	vars := map[string]any{
 		"board_id":      graphql.ToID(boardID),
@@ -152,7 +226,7 @@ func (m *MondayAPIClient) CreateLogItem(boardID int, groupID, itemName, hours st
 		"column_values": JSONEncodedString(columnValues),
 	}
 	var update CreateLogItemMutate
-	err = m.client.Mutate(context.TODO(), &update, vars)
+	err = m.client.Mutate(mutationContext(ctx), &update, vars)
 	if err != nil {
 		return nil, WrapWithStackF(err,
 			"A problem occurred when contacting monday.com. Please verify on monday.com whether a log entry was created or not. Exiting.")
@@ -160,6 +234,222 @@ func (m *MondayAPIClient) CreateLogItem(boardID int, groupID, itemName, hours st
 	return &update, nil
 }
 
+//	query {
+//		me {
+//	 		id
+//	 		name
+//	 		url
+//		}
+//	}
+type Me struct {
+	ID   string
+	Name string
+	URL  string
+}
+
+type GetMeQuery struct {
+	Me Me
+}
+
+// Me calls the Monday API "me" query, returning the user the access token belongs to.
+func (m *MondayAPIClient) Me(ctx context.Context) (*Me, error) {
+	var gmq GetMeQuery
+	err := m.client.Query(ctx, &gmq, nil)
+	if err != nil {
+		return nil, WrapWithStackF(err,
+			"A problem occurred when contacting monday.com. Verify the access token. Exiting.")
+	}
+	return &gmq.Me, nil
+}
+
+//	query {
+//		users(ids: [123456789]) {
+//	 		id
+//	 		name
+//	 		url
+//		}
+//	}
+type GetUsersQuery struct {
+	Users []Me `graphql:"users(ids: $user_ids)"`
+}
+
+// GetUser calls the Monday API "users" query with a single user and returns it.
+func (m *MondayAPIClient) GetUser(ctx context.Context, userID string) (*Me, error) {
+	vars := map[string]any{
+		"user_ids": []graphql.ID{graphql.ToID(userID)},
+	}
+	var guq GetUsersQuery
+	err := m.client.Query(ctx, &guq, vars)
+	if err != nil {
+		return nil, WrapWithStackF(err,
+			"A problem occurred when contacting monday.com. Exiting.")
+	}
+	if len(guq.Users) == 0 {
+		return nil, WithStackF("logging_user_id = %s: user not found on monday.com. Exiting.", userID)
+	}
+	return &guq.Users[0], nil
+}
+
+//	query {
+//	  items(ids: [5678901237]) {
+//	    id
+//	    name
+//	    relative_link
+//	    board { id }
+//	    group { id title }
+//	    column_values(ids: "hours-column") { text }
+//	    person: column_values(ids: "person-column") { value }
+//	  }
+//	}
+type LogItem struct {
+	ID            string
+	Name          string
+	Relative_Link string
+	Board         struct {
+		ID string
+	}
+	Group struct {
+		ID    string
+		Title string
+	}
+	Column_Values []struct {
+		Text string
+	} `graphql:"column_values(ids: $hours_column_id)"`
+	// The value of a people column is JSON, e.g. {"personsAndTeams":[{"id":123,"kind":"person"}]}.
+	Person []struct {
+		Value string
+	} `graphql:"person: column_values(ids: $person_column_id)"`
+}
+
+type GetLogItemQuery struct {
+	Items []LogItem `graphql:"items(ids: $pulse_ids)"`
+}
+
+// GetLogItem calls the Monday API "items" query with a single pulse and returns it.
+func (m *MondayAPIClient) GetLogItem(ctx context.Context, pulseID string) (*LogItem, error) {
+	vars := map[string]any{
+		"pulse_ids":        []graphql.ID{graphql.ToID(pulseID)},
+		"hours_column_id":  []string{m.hoursColumnID},
+		"person_column_id": []string{m.personColumnID},
+	}
+	var gliq GetLogItemQuery
+	err := m.client.Query(ctx, &gliq, vars)
+	if err != nil {
+		return nil, WrapWithStackF(err,
+			"A problem occurred when contacting monday.com. Exiting.")
+	}
+	if len(gliq.Items) == 0 {
+		return nil, WithStackF("pulse_id = %s: pulse not found on monday.com. Exiting.", pulseID)
+	}
+	return &gliq.Items[0], nil
+}
+
+// IsLoggedByUser tells whether the pulse's person column holds the logging user.
+func (m *MondayAPIClient) IsLoggedByUser(item *LogItem) bool {
+	for _, column := range item.Person {
+		var value struct {
+			PersonsAndTeams []struct {
+				ID   json.Number
+				Kind string
+			}
+		}
+		if json.Unmarshal([]byte(column.Value), &value) != nil {
+			continue
+		}
+		for _, person := range value.PersonsAndTeams {
+			if person.Kind == "person" && person.ID.String() == m.loggingUserID {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+type UpdateLogItemMutate struct {
+	Change_Multiple_Column_Values struct {
+		ID string
+	} `graphql:"change_multiple_column_values(board_id: $board_id, item_id: $item_id, column_values: $column_values)"`
+}
+
+// UpdateLogItem calls the Monday API "change_multiple_column_values" mutation to change the name
+// and/or hours of a pulse. Nil values are left unchanged.
+func (m *MondayAPIClient) UpdateLogItem(ctx context.Context, boardID, pulseID string, itemName *string, hours *float64) error {
+	values := map[string]any{}
+	if itemName != nil {
+		values["name"] = *itemName
+	}
+	if hours != nil {
+		if !(*hours > 0 && *hours <= maxHours) {
+			return WithStackF("hours = %s: expected more than 0 and at most %d hours. Exiting.", formatHours(*hours), maxHours)
+		}
+		values[m.hoursColumnID] = *hours
+	}
+	columnValues, err := json.Marshal(values)
+	if err != nil {
+		return WrapWithStack(err, "Unable to encode column values. Exiting.")
+	}
+
+	vars := map[string]any{
+		"board_id":      graphql.ToID(boardID),
+		"item_id":       graphql.ToID(pulseID),
+		"column_values": JSONEncodedString(columnValues),
+	}
+	var update UpdateLogItemMutate
+	err = m.client.Mutate(mutationContext(ctx), &update, vars)
+	if err != nil {
+		return WrapWithStackF(err,
+			"A problem occurred when contacting monday.com. Please verify on monday.com whether the pulse was updated or not. Exiting.")
+	}
+	return nil
+}
+
+type MoveLogItemMutate struct {
+	Move_Item_To_Group struct {
+		ID string
+	} `graphql:"move_item_to_group(item_id: $item_id, group_id: $group_id)"`
+}
+
+// MoveLogItem calls the Monday API "move_item_to_group" mutation to move a pulse to another group
+// of its board.
+func (m *MondayAPIClient) MoveLogItem(ctx context.Context, pulseID, groupID string) error {
+	vars := map[string]any{
+		"item_id":  graphql.ToID(pulseID),
+		"group_id": groupID,
+	}
+	var update MoveLogItemMutate
+	err := m.client.Mutate(mutationContext(ctx), &update, vars)
+	if err != nil {
+		return WrapWithStackF(err,
+			"A problem occurred when contacting monday.com. Please verify on monday.com whether the pulse was moved or not. Exiting.")
+	}
+	return nil
+}
+
+type DeleteLogItemMutate struct {
+	Delete_Item struct {
+		ID string
+	} `graphql:"delete_item(item_id: $item_id)"`
+}
+
+// DeleteLogItem calls the Monday API "delete_item" mutation.
+func (m *MondayAPIClient) DeleteLogItem(ctx context.Context, pulseID string) error {
+	vars := map[string]any{
+		"item_id": graphql.ToID(pulseID),
+	}
+	var update DeleteLogItemMutate
+	err := m.client.Mutate(mutationContext(ctx), &update, vars)
+	if err != nil {
+		return WrapWithStackF(err,
+			"A problem occurred when contacting monday.com. Please verify on monday.com whether the pulse was deleted or not. Exiting.")
+	}
+	return nil
+}
+
+// PulseLink turns a relative link returned by the Monday API into a link for the browser.
+func (m *MondayAPIClient) PulseLink(relativeLink string) string {
+	return m.webURL + relativeLink
+}
+
 //	query {
 //		items(ids: [5244659133]) {
 //			relative_link
@@ -173,15 +463,18 @@ type GetPulseRelativeLinkQuery struct {
 	PRL []PulseRelativeLink `graphql:"items(ids: $pulse_ids)"`
 }
 
-func (m *MondayAPIClient) GetPulseRelativeLink(pulseID string) (*PulseRelativeLink, error) {
+func (m *MondayAPIClient) GetPulseRelativeLink(ctx context.Context, pulseID string) (*PulseRelativeLink, error) {
 	vars := map[string]any{
 		"pulse_ids": []graphql.ID{graphql.ToID(pulseID)},
 	}
 	var gprlq GetPulseRelativeLinkQuery
-	err := m.client.Query(context.TODO(), &gprlq, vars)
+	err := m.client.Query(ctx, &gprlq, vars)
 	if err != nil {
 		return nil, WrapWithStackF(err,
 			"A problem occurred when contacting monday.com. Exiting.")
 	}
+	if len(gprlq.PRL) == 0 {
+		return nil, WithStackF("pulse_id = %s: pulse not found on monday.com. Exiting.", pulseID)
+	}
 	return &gprlq.PRL[0], nil
 }
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/cheynewallace/tabby"
+	"github.com/urfave/cli/v2"
+)
+
+// Output formats accepted by the global --output flag.
+const (
+	outputTable = "table"
+	outputJSON  = "json"
+	outputCSV   = "csv"
+	outputTSV   = "tsv"
+)
+
+// infoWriter receives progress messages (matched lines, links of created pulses, ...). It's
+// stdout for table output, and stderr otherwise so that stdout only holds machine-readable data.
+var infoWriter io.Writer = os.Stdout
+
+// Column describes one field of a Record. Name is used for CSV/TSV headers and matches the JSON
+// field name. Columns without a Header are left out of table output.
+type Column struct {
+	Header string
+	Name   string
+}
+
+// Record is one row of command output. Fields returns one value per Column, in the same order.
+// JSON output encodes the record itself, so its json tags must match the column names.
+type Record interface {
+	Fields() []any
+}
+
+// outputFormat returns the validated --output value and points infoWriter to the right place.
+func outputFormat(cCtx *cli.Context) (string, error) {
+	format := cCtx.String("output")
+	switch format {
+	case outputTable:
+		infoWriter = os.Stdout
+	case outputJSON, outputCSV, outputTSV:
+		infoWriter = os.Stderr
+	default:
+		return "", WithStackF("--output = %s: expected one of table, json, csv or tsv. Exiting.", format)
+	}
+	return format, nil
+}
+
+// printRecords prints the records to stdout in the given output format.
+func printRecords[R Record](format string, columns []Column, records []R) error {
+	switch format {
+	case outputJSON:
+		if records == nil {
+			records = []R{}
+		}
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(records)
+	case outputCSV, outputTSV:
+		writer := csv.NewWriter(os.Stdout)
+		if format == outputTSV {
+			writer.Comma = '\t'
+		}
+		row := make([]string, len(columns))
+		for i, column := range columns {
+			row[i] = column.Name
+		}
+		writer.Write(row)
+		for _, record := range records {
+			for i, field := range record.Fields() {
+				row[i] = fmt.Sprint(field)
+			}
+			writer.Write(row)
+		}
+		writer.Flush()
+		return writer.Error()
+	}
+
+	table := tabby.New()
+	var headers []any
+	for _, column := range columns {
+		if column.Header != "" {
+			headers = append(headers, column.Header)
+		}
+	}
+	table.AddHeader(headers...)
+	for _, record := range records {
+		var line []any
+		for i, field := range record.Fields() {
+			if columns[i].Header != "" {
+				line = append(line, field)
+			}
+		}
+		table.AddLine(line...)
+	}
+	table.Print()
+	return nil
+}
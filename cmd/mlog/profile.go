@@ -0,0 +1,131 @@
+package main
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Profile is a named user configuration ([profiles.<name>] in config.toml), for logging with
+// another monday.com account or on behalf of a colleague. It is selected with --profile or
+// MLOG_PROFILE, and replaces the top-level credentials. Holidays and targets are shared.
+type Profile struct {
+	APIAccessToken        string `toml:"api_access_token,omitempty"`
+	APIAccessTokenCommand string `toml:"api_access_token_command,omitempty"`
+	LoggingUserID         string `toml:"logging_user_id"`
+	// Relative to the directory of config.toml. Defaults to the shared boards.toml.
+	BoardsFile string `toml:"boards_file,omitempty"`
+	// Where mlog update fetches boards_file from. Without it, a profile with its own boards_file
+	// isn't updated (the shared boards.toml is for magicboard).
+	BoardsURL string `toml:"boards_url,omitempty"`
+	// Default to the top-level ones.
+	APIURL     string `toml:"api_url,omitempty"`
+	APIVersion string `toml:"api_version,omitempty"`
+	WebURL     string `toml:"web_url,omitempty"`
+}
+
+// profileName is the profile selected with --profile or MLOG_PROFILE. Empty means the top-level
+// configuration.
+var profileName string
+
+// withProfile returns a copy of the configuration with the token, user and endpoints of the named
+// profile. The empty name returns the top-level configuration.
+func (c *UserConf) withProfile(name string) (*UserConf, error) {
+	conf := *c
+	conf.Profiles = nil
+	conf.profile = name
+	if name == "" {
+		return &conf, nil
+	}
+	profile, ok := c.Profiles[name]
+	if !ok {
+		return nil, WithStackF("--profile = %s: not found in %s (profiles: %s). Exiting.",
+			name, userConfFilePath, strings.Join(c.profileNames(), ", "))
+	}
+	conf.APIAccessToken = profile.APIAccessToken
+	conf.APIAccessTokenCommand = profile.APIAccessTokenCommand
+	conf.LoggingUserID = profile.LoggingUserID
+	for _, endpoint := range []struct{ value, override *string }{
+		{&conf.APIURL, &profile.APIURL},
+		{&conf.APIVersion, &profile.APIVersion},
+		{&conf.WebURL, &profile.WebURL},
+	} {
+		if *endpoint.override != "" {
+			*endpoint.value = *endpoint.override
+		}
+	}
+	return &conf, nil
+}
+
+// credentials returns the fields setup writes for the named profile (top-level when empty).
+func (c *UserConf) credentials(name string) (apiAccessToken, loggingUserID *string) {
+	if name == "" {
+		return &c.APIAccessToken, &c.LoggingUserID
+	}
+	profile := c.Profiles[name]
+	return &profile.APIAccessToken, &profile.LoggingUserID
+}
+
+// usesTopLevel tells whether the top-level credentials are configured, or expected to be: a
+// configuration can also hold only profiles.
+func (c *UserConf) usesTopLevel() bool {
+	return len(c.Profiles) == 0 || c.APIAccessToken != "" || c.APIAccessTokenCommand != "" || c.LoggingUserID != ""
+}
+
+func (c *UserConf) profileNames() []string {
+	names := make([]string, 0, len(c.Profiles))
+	for name := range c.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// validateProfiles checks what can be checked without running commands or reading the keyring.
+func (c *UserConf) validateProfiles() error {
+	for _, name := range c.profileNames() {
+		profile := c.Profiles[name]
+		if profile == nil || profile.LoggingUserID == "" {
+			return WithStackF("profiles.%s.logging_user_id: missing in %s.\nRun `mlog setup` for error details.", name, userConfFilePath)
+		}
+		if profile.BoardsURL != "" && profile.BoardsFile == "" {
+			return WithStackF("profiles.%s.boards_url: needs boards_file, to not replace the shared boards.toml. Exiting.", name)
+		}
+	}
+	return nil
+}
+
+// boardsFilePath returns the boards configuration file of the named profile.
+func (c *UserConf) boardsFilePath(name string) string {
+	profile := c.Profiles[name]
+	if name == "" || profile == nil || profile.BoardsFile == "" {
+		return boardsConfFilePath
+	}
+	if filepath.IsAbs(profile.BoardsFile) {
+		return profile.BoardsFile
+	}
+	return filepath.Join(filepath.Dir(userConfFilePath), profile.BoardsFile)
+}
+
+// boardsURL returns where mlog update fetches the boards configuration file of the named profile.
+func (c *UserConf) boardsURL(name string) (string, error) {
+	profile := c.Profiles[name]
+	if name == "" || profile == nil || profile.BoardsFile == "" {
+		return defaultBoardsURL, nil
+	}
+	if profile.BoardsURL == "" {
+		return "", WithStackF("profiles.%s.boards_url: missing, so %s can't be updated. Exiting.", name, c.boardsFilePath(name))
+	}
+	return profile.BoardsURL, nil
+}
+
+// selectProfile returns the configuration of the selected profile and points boardsConfFilePath to
+// its boards configuration file.
+func (c *UserConf) selectProfile() (*UserConf, error) {
+	conf, err := c.withProfile(profileName)
+	if err != nil {
+		return nil, err
+	}
+	boardsConfFilePath = c.boardsFilePath(profileName)
+	return conf, nil
+}
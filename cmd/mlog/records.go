@@ -0,0 +1,133 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// csvColumnNames maps accepted CSV header names to the column they represent.
+var csvColumnNames = map[string]string{
+	"date":        "date",
+	"day":         "date",
+	"description": "description",
+	"name":        "description",
+	"item_name":   "description",
+	"hours":       "hours",
+}
+
+// readCSVRows reads "date,description,hours" records. When the first record is a header (naming the
+// three columns), columns are matched by name and can come in any order, along with other columns
+// that get ignored. Otherwise columns are taken positionally.
+func readCSVRows(r io.Reader) ([]InputRow, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	columns := map[string]int{"date": 0, "description": 1, "hours": 2}
+	var rows []InputRow
+	for recordNumber := 1; ; recordNumber++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, WrapWithStackF(err, "record %d: unable to parse CSV. Exiting.", recordNumber)
+		}
+		line, _ := reader.FieldPos(0)
+
+		if recordNumber == 1 {
+			if header, ok := csvHeader(record); ok {
+				fmt.Fprintf(infoWriter, "line %d: header detected: %s\n", line, strings.Join(record, ","))
+				columns = header
+				continue
+			}
+		}
+
+		field := func(name string) string {
+			if i := columns[name]; i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+		row := InputRow{
+			LineNumber:  uint(line),
+			DayYYYYMMDD: field("date"),
+			ItemName:    field("description"),
+			Hours:       field("hours"),
+		}
+		if row.DayYYYYMMDD == "" || row.ItemName == "" || row.Hours == "" {
+			return nil, WithStackF("line %d (record %d): expected date, description and hours columns. Exiting.", line, recordNumber)
+		}
+		fmt.Fprintf(infoWriter, "line %d: matched CSV record: %s, %s, %s\n", line, row.DayYYYYMMDD, row.ItemName, row.Hours)
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// csvHeader returns the column indexes when the record is a header naming every needed column.
+// Unknown names are other columns (e.g. notes), and a column named twice is taken from the first.
+func csvHeader(record []string) (map[string]int, bool) {
+	columns := map[string]int{}
+	for i, name := range record {
+		column, ok := csvColumnNames[strings.ToLower(strings.TrimSpace(name))]
+		if _, seen := columns[column]; ok && !seen {
+			columns[column] = i
+		}
+	}
+	return columns, len(columns) == 3
+}
+
+// JSONRecord is one line of JSON Lines input. Hours can be a number or a string, and get validated
+// along with the rest of the rows.
+type JSONRecord struct {
+	Date        string          `json:"date"`
+	Description string          `json:"description"`
+	Hours       json.RawMessage `json:"hours"`
+}
+
+// readJSONLinesRows reads one {"date": ..., "description": ..., "hours": ...} object per line.
+// Blank lines are skipped.
+func readJSONLinesRows(r io.Reader) ([]InputRow, error) {
+	var rows []InputRow
+	var lineNumber uint
+	scanner := bufio.NewScanner(r)
+	scanner.Split(bufio.ScanLines)
+	for scanner.Scan() {
+		lineNumber += 1
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+
+		var record JSONRecord
+		err := json.Unmarshal(line, &record)
+		if err != nil {
+			return nil, WrapWithStackF(err, "line %d: unable to parse JSON record (%v). Exiting.", lineNumber, err)
+		}
+		hours := string(record.Hours)
+		var hoursString string
+		if json.Unmarshal(record.Hours, &hoursString) == nil {
+			hours = hoursString
+		}
+		if record.Date == "" || record.Description == "" || hours == "" || hours == "null" {
+			return nil, WithStackF("line %d: expected \"date\", \"description\" and \"hours\" fields. Exiting.", lineNumber)
+		}
+		row := InputRow{
+			LineNumber:  lineNumber,
+			DayYYYYMMDD: record.Date,
+			ItemName:    record.Description,
+			Hours:       hours,
+		}
+		fmt.Fprintf(infoWriter, "line %d: matched JSON record: %s, %s, %s\n", lineNumber, row.DayYYYYMMDD, row.ItemName, row.Hours)
+		rows = append(rows, row)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, WrapWithStack(err, "scanned stdin lines")
+	}
+	return rows, nil
+}
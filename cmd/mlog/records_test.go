@@ -0,0 +1,81 @@
+package main
+
+import (
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// discardInfo silences the progress messages of the readers for the duration of the test.
+func discardInfo(t *testing.T) {
+	previous := infoWriter
+	infoWriter = io.Discard
+	t.Cleanup(func() { infoWriter = previous })
+}
+
+func TestReadCSVRows(t *testing.T) {
+	discardInfo(t)
+	tests := []struct {
+		name    string
+		input   string
+		want    []InputRow
+		wantErr bool
+	}{
+		{
+			name:  "positional",
+			input: "2023-09-05,Demo,1.5\n2023-09-06, \"Review, notes\",2\n",
+			want: []InputRow{
+				{LineNumber: 1, DayYYYYMMDD: "2023-09-05", ItemName: "Demo", Hours: "1.5"},
+				{LineNumber: 2, DayYYYYMMDD: "2023-09-06", ItemName: "Review, notes", Hours: "2"},
+			},
+		},
+		{
+			name:  "header",
+			input: "date,description,hours\n2023-09-05,Demo,1.5\n",
+			want:  []InputRow{{LineNumber: 2, DayYYYYMMDD: "2023-09-05", ItemName: "Demo", Hours: "1.5"}},
+		},
+		{
+			name:  "reordered header",
+			input: "Hours, Name, Day\n1:30,Demo,2023-09-05\n",
+			want:  []InputRow{{LineNumber: 2, DayYYYYMMDD: "2023-09-05", ItemName: "Demo", Hours: "1:30"}},
+		},
+		{
+			name:  "header with other columns",
+			input: "date,description,hours,notes\n2023-09-05,Demo,1.5,prepared slides\n",
+			want:  []InputRow{{LineNumber: 2, DayYYYYMMDD: "2023-09-05", ItemName: "Demo", Hours: "1.5"}},
+		},
+		{
+			name:  "header with other columns first",
+			input: "id,Hours,Day,Name\n7,2,2023-09-05,Demo\n",
+			want:  []InputRow{{LineNumber: 2, DayYYYYMMDD: "2023-09-05", ItemName: "Demo", Hours: "2"}},
+		},
+		{
+			name:    "missing column",
+			input:   "2023-09-05,Demo\n",
+			wantErr: true,
+		},
+		{
+			name:    "incomplete header",
+			input:   "date,hours\n2023-09-05,1\n",
+			wantErr: true,
+		},
+		{
+			name:    "unterminated quote",
+			input:   "2023-09-05,\"Demo,1\n",
+			wantErr: true,
+		},
+	}
+	for _, test := range tests {
+		got, err := readCSVRows(strings.NewReader(test.input))
+		if test.wantErr {
+			if err == nil {
+				t.Errorf("%s: readCSVRows() = %+v, want an error", test.name, got)
+			}
+			continue
+		}
+		if err != nil || !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s: readCSVRows() = %+v, %v, want %+v", test.name, got, err, test.want)
+		}
+	}
+}
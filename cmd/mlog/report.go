@@ -0,0 +1,103 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/urfave/cli/v2"
+)
+
+// ReportRecord is one line of the report: the totals of a month, an ISO week, or the whole
+// requested range (Type is month, week or total).
+type ReportRecord struct {
+	Type       string  `json:"type"`
+	Period     string  `json:"period"`
+	TotalHours float64 `json:"total_hours"`
+	PulseCount int     `json:"pulse_count"`
+}
+
+var reportColumns = []Column{
+	{"TYPE", "type"},
+	{"PERIOD", "period"},
+	{"TOTAL HOURS", "total_hours"},
+	{"PULSE COUNT", "pulse_count"},
+}
+
+func (r ReportRecord) Fields() []any {
+	return []any{r.Type, r.Period, r.TotalHours, r.PulseCount}
+}
+
+// noDayPeriod is the week of items whose group isn't mapped to a day in the boards configuration.
+const noDayPeriod = "(no day)"
+
+func cliReport(cCtx *cli.Context) error {
+	format, err := outputFormat(cCtx)
+	if err != nil {
+		return err
+	}
+	from, to := cCtx.String("from"), cCtx.String("to")
+	if to == "" {
+		to = from
+	}
+	period, err := parsePeriod(from + ".." + to)
+	if err != nil || !period.WholeMonths {
+		return WithStackF("--from = %s, --to = %s: expected months in format yyyy-mm. Exiting.", from, to)
+	}
+	userConf, boardsConf, err := loadConf()
+	if err != nil {
+		return err
+	}
+
+	mondayAPIClient := NewMondayAPIClient(userConf, boardsConf, cCtx.Duration("timeout"))
+
+	months, err := fetchPeriodItems(cCtx, mondayAPIClient, boardsConf, period, cCtx.Int("workers"))
+	if err != nil {
+		return err
+	}
+
+	var monthRecords []ReportRecord
+	weekTotals := map[string]*ReportRecord{}
+	total := ReportRecord{Type: "total", Period: fmt.Sprintf("%s..%s", from, to)}
+	for _, month := range months {
+		monthRecord := ReportRecord{Type: "month", Period: month.MonthYYYYMM}
+		for _, item := range month.Items {
+			hours, err := itemHours(item)
+			if err != nil {
+				return err
+			}
+			week := noDayPeriod
+			if day, ok := month.Days.Lookup(item.Group.ID, item.Group.Title); ok {
+				year, weekNumber := day.ISOWeek()
+				week = fmt.Sprintf("%d-W%02d", year, weekNumber)
+			}
+			weekRecord := weekTotals[week]
+			if weekRecord == nil {
+				weekRecord = &ReportRecord{Type: "week", Period: week}
+				weekTotals[week] = weekRecord
+			}
+
+			for _, record := range []*ReportRecord{&monthRecord, weekRecord, &total} {
+				record.TotalHours += hours
+				record.PulseCount += 1
+			}
+		}
+		monthRecords = append(monthRecords, monthRecord)
+	}
+
+	weeks := make([]string, 0, len(weekTotals))
+	for week := range weekTotals {
+		weeks = append(weeks, week)
+	}
+	// yyyy-Www sorts chronologically as a string, and "(no day)" sorts first.
+	slices.Sort(weeks)
+
+	records := monthRecords
+	for _, week := range weeks {
+		records = append(records, *weekTotals[week])
+	}
+	records = append(records, total)
+	for i := range records {
+		records[i].TotalHours = roundHundredths(records[i].TotalHours)
+	}
+	return printRecords(format, reportColumns, records)
+}
@@ -0,0 +1,182 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"os"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/hasura/go-graphql-client"
+)
+
+const (
+	maxAttempts      = 5
+	retryBaseDelay   = time.Second
+	retryMaxDelay    = 30 * time.Second
+	rateLimitWaitCap = 2 * time.Minute
+)
+
+// Example error message:
+// Complexity budget exhausted, query cost 30001 budget remaining 2999 out of 1000000 reset in 21 seconds
+var regexComplexityReset = regexp.MustCompile(`(?:ComplexityException|Complexity budget exhausted)[\s\S]*?reset in ([[:digit:]]+) seconds?`)
+
+type mutationContextKey struct{}
+
+// mutationContext marks the requests made with the returned context as mutations. retryDoer
+// doesn't retry them after an ambiguous failure (the mutation may have been applied). The
+// cancellation of ctx (Ctrl-C) stops them from being sent, but doesn't abort them once sent, so
+// that their outcome is known. --timeout still applies (see retryDoer).
+func mutationContext(ctx context.Context) context.Context {
+	return context.WithValue(uninterruptibleContext{ctx}, mutationContextKey{}, ctx)
+}
+
+// interruptContext returns the context whose cancellation stops the request from being sent, and
+// whether the request is a mutation.
+func interruptContext(ctx context.Context) (context.Context, bool) {
+	if parent, ok := ctx.Value(mutationContextKey{}).(context.Context); ok {
+		return parent, true
+	}
+	return ctx, false
+}
+
+// uninterruptibleContext keeps the values of its parent, but not its deadline and cancellation.
+type uninterruptibleContext struct {
+	context.Context
+}
+
+func (uninterruptibleContext) Deadline() (time.Time, bool) { return time.Time{}, false }
+func (uninterruptibleContext) Done() <-chan struct{}       { return nil }
+func (uninterruptibleContext) Err() error                  { return nil }
+
+// retryDoer is the HTTP client of the GraphQL client. It retries requests that monday.com rejected
+// because of its rate limit (429, waiting as long as Retry-After asks) or complexity budget (waiting
+// until the budget resets). Those requests were never run, so mutations are retried too.
+//
+// Network errors and 5xx responses are ambiguous: only queries are retried, with exponential
+// backoff and jitter.
+//
+// The timeout (--timeout, 0 means none) covers all the attempts of a request and the waits between
+// them.
+type retryDoer struct {
+	doer    graphql.Doer
+	timeout time.Duration
+}
+
+func (d *retryDoer) Do(req *http.Request) (*http.Response, error) {
+	ctx, mutation := interruptContext(req.Context())
+	if d.timeout > 0 {
+		deadline := time.Now().Add(d.timeout)
+		var cancel, cancelRequest context.CancelFunc
+		ctx, cancel = context.WithDeadline(ctx, deadline)
+		defer cancel()
+		var requestCtx context.Context
+		requestCtx, cancelRequest = context.WithDeadline(req.Context(), deadline)
+		defer cancelRequest()
+		req = req.WithContext(requestCtx)
+	}
+	for attempt := 1; ; attempt++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if attempt > 1 && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req.Body = body
+		}
+		resp, err := d.doer.Do(req)
+		if err != nil {
+			if attempt == maxAttempts || ctx.Err() != nil || mutation {
+				return nil, err
+			}
+			err = d.wait(ctx, attempt, backoff(attempt), err.Error())
+			if err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		// The body is read to look for the complexity budget error, which can come with a 200
+		// status, and handed over as is.
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		resp.Body = io.NopCloser(bytes.NewReader(body))
+		if attempt == maxAttempts {
+			return resp, nil
+		}
+
+		var wait time.Duration
+		var reason string
+		if matches := regexComplexityReset.FindSubmatch(body); matches != nil {
+			seconds, _ := strconv.Atoi(string(matches[1]))
+			wait, reason = capWait(time.Duration(seconds+1)*time.Second), "complexity budget exhausted"
+		} else if resp.StatusCode == http.StatusTooManyRequests {
+			wait, reason = retryAfter(resp.Header.Get("Retry-After"), attempt), "rate limit reached"
+		} else if resp.StatusCode >= 500 && !mutation {
+			wait, reason = backoff(attempt), resp.Status
+		} else {
+			return resp, nil
+		}
+		err = d.wait(ctx, attempt, wait, reason)
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+// wait sleeps before the next attempt, unless the context is done first. It gives up right away
+// when the next attempt would start after the deadline.
+func (d *retryDoer) wait(ctx context.Context, attempt int, wait time.Duration, reason string) error {
+	if deadline, ok := ctx.Deadline(); ok && time.Now().Add(wait).After(deadline) {
+		return fmt.Errorf("%s, and retrying in %s would exceed --timeout %s", reason, wait.Round(time.Second), d.timeout)
+	}
+	logger.Debugw("Retrying monday.com request", "attempt", attempt, "wait", wait, "reason", reason)
+	fmt.Fprintf(os.Stderr, "monday.com: %s, retrying in %s (attempt %d of %d)\n", reason, wait.Round(time.Second), attempt+1, maxAttempts)
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(wait):
+		return nil
+	}
+}
+
+// backoff returns a random delay up to retryBaseDelay doubled for every attempt made, capped at
+// retryMaxDelay ("full jitter").
+func backoff(attempt int) time.Duration {
+	ceiling := retryBaseDelay << (attempt - 1)
+	if ceiling > retryMaxDelay || ceiling <= 0 {
+		ceiling = retryMaxDelay
+	}
+	return time.Duration(rand.Int63n(int64(ceiling))) + time.Millisecond
+}
+
+// retryAfter reads a Retry-After header (seconds or HTTP date), falling back to backoff.
+func retryAfter(header string, attempt int) time.Duration {
+	if seconds, err := strconv.Atoi(header); err == nil && seconds >= 0 {
+		return capWait(time.Duration(seconds) * time.Second)
+	}
+	if date, err := http.ParseTime(header); err == nil {
+		return capWait(time.Until(date))
+	}
+	return backoff(attempt)
+}
+
+func capWait(wait time.Duration) time.Duration {
+	if wait > rateLimitWaitCap {
+		return rateLimitWaitCap
+	}
+	if wait < 0 {
+		return 0
+	}
+	return wait
+}
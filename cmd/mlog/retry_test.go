@@ -0,0 +1,197 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// testResponse is what the test server answers to an attempt.
+type testResponse struct {
+	status     int
+	retryAfter string
+	body       string
+	// Closes the connection without answering, for a network error.
+	drop  bool
+	delay time.Duration
+}
+
+func TestRetryDoer(t *testing.T) {
+	logger = zap.NewNop().Sugar()
+	ok := testResponse{status: http.StatusOK, body: `{"data":{}}`}
+	tests := []struct {
+		name      string
+		responses []testResponse // The last one answers the remaining attempts.
+		mutation  bool
+		timeout   time.Duration
+		// -1 when it depends on the random backoff.
+		wantAttempts int
+		wantStatus   int
+		wantErr      bool
+		minElapsed   time.Duration
+		maxElapsed   time.Duration
+	}{
+		{
+			name:         "query retried after 5xx",
+			responses:    []testResponse{{status: http.StatusBadGateway}, ok},
+			wantAttempts: 2,
+			wantStatus:   http.StatusOK,
+		},
+		{
+			name:         "mutation not retried after 5xx",
+			responses:    []testResponse{{status: http.StatusBadGateway}, ok},
+			mutation:     true,
+			wantAttempts: 1,
+			wantStatus:   http.StatusBadGateway,
+		},
+		{
+			name:         "query retried after network error",
+			responses:    []testResponse{{drop: true}, ok},
+			wantAttempts: 2,
+			wantStatus:   http.StatusOK,
+		},
+		{
+			name:         "mutation not retried after network error",
+			responses:    []testResponse{{drop: true}, ok},
+			mutation:     true,
+			wantAttempts: 1,
+			wantErr:      true,
+		},
+		{
+			name:         "mutation retried after 429",
+			responses:    []testResponse{{status: http.StatusTooManyRequests, retryAfter: "0"}, ok},
+			mutation:     true,
+			wantAttempts: 2,
+			wantStatus:   http.StatusOK,
+		},
+		{
+			name:         "429 waits for Retry-After",
+			responses:    []testResponse{{status: http.StatusTooManyRequests, retryAfter: "1"}, ok},
+			wantAttempts: 2,
+			wantStatus:   http.StatusOK,
+			minElapsed:   time.Second,
+		},
+		{
+			name:         "Retry-After past the timeout",
+			responses:    []testResponse{{status: http.StatusTooManyRequests, retryAfter: "60"}, ok},
+			timeout:      5 * time.Second,
+			wantAttempts: 1,
+			wantErr:      true,
+			maxElapsed:   time.Second,
+		},
+		{
+			name: "mutation waits for the complexity budget",
+			responses: []testResponse{
+				{status: http.StatusOK, body: `{"errors":[{"message":"Complexity budget exhausted, query cost 30001 budget remaining 2999 out of 1000000 reset in 0 seconds"}]}`},
+				ok,
+			},
+			mutation:     true,
+			wantAttempts: 2,
+			wantStatus:   http.StatusOK,
+			// One second more than the reset, for rounding.
+			minElapsed: time.Second,
+		},
+		{
+			name: "complexity budget reset past the timeout",
+			responses: []testResponse{
+				{status: http.StatusOK, body: `{"error_code":"ComplexityException","error_message":"Complexity budget exhausted, reset in 60 seconds"}`},
+				ok,
+			},
+			timeout:      5 * time.Second,
+			wantAttempts: 1,
+			wantErr:      true,
+			maxElapsed:   time.Second,
+		},
+		{
+			name:         "timeout covers all attempts",
+			responses:    []testResponse{{status: http.StatusInternalServerError, delay: 400 * time.Millisecond}},
+			timeout:      time.Second,
+			wantAttempts: -1,
+			wantErr:      true,
+			maxElapsed:   1500 * time.Millisecond,
+		},
+		{
+			name:         "timeout covers a response that doesn't come",
+			responses:    []testResponse{{status: http.StatusOK, delay: time.Minute}},
+			mutation:     true,
+			timeout:      500 * time.Millisecond,
+			wantAttempts: 1,
+			wantErr:      true,
+			maxElapsed:   time.Second,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var attempts atomic.Int32
+			// Ends the delays of the handler, which the client giving up doesn't always interrupt.
+			done := make(chan struct{})
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				response := test.responses[len(test.responses)-1]
+				if attempt := int(attempts.Add(1)); attempt < len(test.responses) {
+					response = test.responses[attempt-1]
+				}
+				select {
+				case <-time.After(response.delay):
+				case <-r.Context().Done():
+					return
+				case <-done:
+					return
+				}
+				if response.drop {
+					conn, _, _ := w.(http.Hijacker).Hijack()
+					conn.Close()
+					return
+				}
+				if response.retryAfter != "" {
+					w.Header().Set("Retry-After", response.retryAfter)
+				}
+				w.WriteHeader(response.status)
+				w.Write([]byte(response.body))
+			}))
+			defer server.Close()
+			defer close(done)
+
+			ctx := context.Background()
+			if test.mutation {
+				ctx = mutationContext(ctx)
+			}
+			req, err := http.NewRequestWithContext(ctx, http.MethodPost, server.URL, strings.NewReader(`{"query":"{}"}`))
+			if err != nil {
+				t.Fatal(err)
+			}
+			doer := &retryDoer{
+				// Without keep-alive, so that the transport doesn't retry on its own.
+				doer:    &http.Client{Transport: &http.Transport{DisableKeepAlives: true}},
+				timeout: test.timeout,
+			}
+			start := time.Now()
+			resp, err := doer.Do(req)
+			elapsed := time.Since(start)
+
+			if test.wantErr {
+				if err == nil {
+					t.Errorf("Do() = %s, want an error", resp.Status)
+				}
+			} else if err != nil {
+				t.Errorf("Do() error: %v", err)
+			} else if resp.StatusCode != test.wantStatus {
+				t.Errorf("Do() = %s, want %d", resp.Status, test.wantStatus)
+			}
+			if got := int(attempts.Load()); test.wantAttempts >= 0 && got != test.wantAttempts {
+				t.Errorf("%d attempt(s), want %d", got, test.wantAttempts)
+			}
+			if elapsed < test.minElapsed {
+				t.Errorf("took %s, want at least %s", elapsed, test.minElapsed)
+			}
+			if test.maxElapsed > 0 && elapsed > test.maxElapsed {
+				t.Errorf("took %s, want at most %s", elapsed, test.maxElapsed)
+			}
+		})
+	}
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io/fs"
+	"os"
+
+	"github.com/adrg/xdg"
+	"github.com/go-errors/errors"
+)
+
+// RunFile tracks which input lines of a create-many run were submitted to monday.com, so that an
+// interrupted run can be resumed with --resume. It's keyed by a hash of the profile and the input,
+// lives under the XDG state directory and gets removed once every line went through.
+type RunFile struct {
+	path      string
+	submitted map[runKey]string
+}
+
+// runKey identifies a row of the input. The day is part of it since one timeclock line can produce
+// rows for several days.
+type runKey struct {
+	lineNumber uint
+	day        string
+}
+
+// RunRecord is one line of a run file: an input row and the pulse created from it.
+type RunRecord struct {
+	LineNumber uint   `json:"line"`
+	Day        string `json:"day"`
+	PulseID    string `json:"pulse_id"`
+}
+
+// OpenRunFile locates the run file for the given profile and input, and loads what a previous run
+// submitted. The same input logged with another profile is another run.
+func OpenRunFile(profile string, input []byte) (*RunFile, error) {
+	hash := sha256.New()
+	if profile != "" {
+		hash.Write([]byte(profile + "\x00"))
+	}
+	hash.Write(input)
+	path, err := xdg.StateFile("mlog/runs/" + hex.EncodeToString(hash.Sum(nil)) + ".jsonl")
+	if err != nil {
+		return nil, WrapWithStack(err, "Error: unable to locate run file. Please send a bug report to the developer. Exiting.")
+	}
+	runFile := &RunFile{path: path, submitted: map[runKey]string{}}
+
+	file, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return runFile, nil
+	} else if err != nil {
+		return nil, WrapWithStackF(err, "Unable to open run file %s. Exiting.", path)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var record RunRecord
+		// A partially written last line is possible if mlog was killed mid-write. Skipping it is
+		// fine since duplicate detection runs again for that line.
+		if json.Unmarshal(scanner.Bytes(), &record) == nil {
+			runFile.submitted[runKey{record.LineNumber, record.Day}] = record.PulseID
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, WrapWithStackF(err, "Unable to read run file %s. Exiting.", path)
+	}
+	return runFile, nil
+}
+
+// Submitted returns the pulse ID created from the row in a previous run, if any.
+func (r *RunFile) Submitted(lineNumber uint, day string) (string, bool) {
+	pulseID, ok := r.submitted[runKey{lineNumber, day}]
+	return pulseID, ok
+}
+
+// HasProgress reports whether a previous run submitted at least one line.
+func (r *RunFile) HasProgress() bool {
+	return len(r.submitted) > 0
+}
+
+// Record appends the row to the run file right after its pulse was created.
+func (r *RunFile) Record(lineNumber uint, day, pulseID string) error {
+	line, err := json.Marshal(&RunRecord{LineNumber: lineNumber, Day: day, PulseID: pulseID})
+	if err != nil {
+		return err
+	}
+	file, err := os.OpenFile(r.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	_, err = file.Write(append(line, '\n'))
+	if err != nil {
+		return err
+	}
+	r.submitted[runKey{lineNumber, day}] = pulseID
+	return file.Close()
+}
+
+// Remove deletes the run file once the whole input went through.
+func (r *RunFile) Remove() error {
+	err := os.Remove(r.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/go-errors/errors"
+	"github.com/pelletier/go-toml/v2"
+	"github.com/urfave/cli/v2"
+	"golang.org/x/term"
+)
+
+// setupUser asks for the access token (unless --token is given or another source already provides
+// one, see apiAccessToken), looks up the user it belongs to with the "me" query, and saves both in
+// the user configuration file (in the profile selected with --profile, if any). An existing
+// logging_user_id is kept, for logging on behalf of a colleague. The user is asked to confirm the
+// name(s), except with --token.
+//
+// With --keyring, the token is stored in the OS keyring instead, and removed from the file. A token
+// from MLOG_API_TOKEN or api_access_token_command is never written to the file.
+func setupUser(cCtx *cli.Context, userConf *UserConf, resolvedToken string) error {
+	// Resolved separately, so that the profile settings and defaults aren't written to the file.
+	endpoints, err := userConf.withProfile(profileName)
+	if err != nil {
+		return err
+	}
+	endpoints.resolveEndpoints()
+
+	stdin := bufio.NewReader(os.Stdin)
+	token := strings.TrimSpace(cCtx.String("token"))
+	interactive := !cCtx.IsSet("token")
+	// Whether the token has to be saved, rather than kept where it comes from.
+	newToken := !interactive
+	if interactive && resolvedToken != "" {
+		token = resolvedToken
+	} else if interactive {
+		token, err = promptToken(stdin, endpoints.WebURL)
+		if err != nil {
+			return err
+		}
+		newToken = true
+	}
+	if token == "" {
+		return WithStack("No access token provided. Exiting.")
+	}
+
+	endpoints.APIAccessToken = token
+	mondayAPIClient := NewMondayAPIClient(endpoints, &BoardsConf{}, cCtx.Duration("timeout"))
+	logger.Debugw("Me")
+	me, err := mondayAPIClient.Me(cCtx.Context)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("✅ Access token belongs to %s (user ID %s, %s)\n", me.Name, me.ID, me.URL)
+
+	apiAccessToken, loggingUserID := userConf.credentials(profileName)
+	// An existing logging_user_id is kept: it can be a colleague's, whose hours are logged on their
+	// behalf with this token.
+	loggingUser := me
+	if *loggingUserID != "" && *loggingUserID != me.ID {
+		logger.Debugw("GetUser", "userID", *loggingUserID)
+		loggingUser, err = mondayAPIClient.GetUser(cCtx.Context, *loggingUserID)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("✅ Hours are logged as %s (logging_user_id %s, %s)\n", loggingUser.Name, loggingUser.ID, loggingUser.URL)
+	}
+	if interactive {
+		question := "Log hours as " + me.Name + "?"
+		if loggingUser != me {
+			question = "Log hours as " + loggingUser.Name + " with the access token of " + me.Name + "?"
+		}
+		ok, err := confirm(stdin, question)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return WithStack("Nothing was saved. Exiting.")
+		}
+	}
+
+	*loggingUserID = loggingUser.ID
+	if cCtx.Bool("keyring") {
+		err = storeKeyringToken(profileName, token)
+		if err != nil {
+			return err
+		}
+		*apiAccessToken = ""
+	} else if newToken {
+		*apiAccessToken = token
+	}
+	backedUp, err := writeUserConf(userConfFilePath, userConf)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("✅ Saved to %s\n", userConfFilePath)
+	if backedUp {
+		fmt.Printf("Previous file kept as %s.bak: comments and unknown settings aren't carried over.\n", filepath.Base(userConfFilePath))
+	}
+	return nil
+}
+
+// promptToken reads the access token without echoing it when stdin is a terminal, or as a line
+// otherwise.
+func promptToken(stdin *bufio.Reader, webURL string) (string, error) {
+	fmt.Printf("Access token (from %s/apps/manage/tokens): ", webURL)
+	fd := int(os.Stdin.Fd())
+	if term.IsTerminal(fd) {
+		token, err := term.ReadPassword(fd)
+		fmt.Println()
+		if err != nil {
+			return "", WrapWithStack(err, "Unable to read the access token. Exiting.")
+		}
+		return strings.TrimSpace(string(token)), nil
+	}
+	line, err := stdin.ReadString('\n')
+	if err != nil && err != io.EOF {
+		return "", WrapWithStack(err, "Unable to read the access token. Exiting.")
+	}
+	fmt.Println()
+	return strings.TrimSpace(line), nil
+}
+
+// writeUserConf replaces the user configuration file (see writeFileAtomic). Only the user can read
+// it (0600), since it holds the access token. The file is written from userConf, so the previous one
+// is kept as a backup (.bak) first; it returns whether there was one.
+func writeUserConf(path string, userConf *UserConf) (bool, error) {
+	content, err := toml.Marshal(userConf)
+	if err != nil {
+		return false, WrapWithStack(err, "Unable to encode the user configuration. Exiting.")
+	}
+
+	previous, err := os.ReadFile(path)
+	backedUp := err == nil
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return false, WrapWithStackF(err, "Unable to read %s. Exiting.", path)
+	}
+	if backedUp {
+		err = os.WriteFile(path+".bak", previous, 0o600)
+		if err != nil {
+			return false, WrapWithStackF(err, "Unable to back up %s. Exiting.", path)
+		}
+	}
+	content = append([]byte("# Written by mlog setup. See config.example.toml for the other settings.\n"), content...)
+	err = writeFileAtomic(path, content, 0o600)
+	if err != nil {
+		return false, WrapWithStackF(err, "Unable to write %s. Exiting.", path)
+	}
+	return backedUp, nil
+}
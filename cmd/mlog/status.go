@@ -0,0 +1,135 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/cheynewallace/tabby"
+	"github.com/urfave/cli/v2"
+)
+
+// StatusRecord is one line of status: the progress of today, the current ISO week or the current
+// month (Type is day, week or month). Expected hours are the target up to today included.
+type StatusRecord struct {
+	Type           string  `json:"type"`
+	Period         string  `json:"period"`
+	LoggedHours    float64 `json:"logged_hours"`
+	ExpectedHours  float64 `json:"expected_hours"`
+	TargetHours    float64 `json:"target_hours"`
+	RemainingHours float64 `json:"remaining_hours"`
+}
+
+var statusColumns = []Column{
+	{"TYPE", "type"},
+	{"PERIOD", "period"},
+	{"LOGGED", "logged_hours"},
+	{"EXPECTED", "expected_hours"},
+	{"TARGET", "target_hours"},
+	{"REMAINING", "remaining_hours"},
+}
+
+func (r StatusRecord) Fields() []any {
+	return []any{r.Type, r.Period, r.LoggedHours, r.ExpectedHours, r.TargetHours, r.RemainingHours}
+}
+
+func newStatusRecord(recordType, period string, logged, expected, target float64) StatusRecord {
+	return StatusRecord{
+		Type:           recordType,
+		Period:         period,
+		LoggedHours:    roundHundredths(logged),
+		ExpectedHours:  roundHundredths(expected),
+		TargetHours:    roundHundredths(target),
+		RemainingHours: roundHundredths(math.Max(target-logged, 0)),
+	}
+}
+
+func roundHundredths(hours float64) float64 {
+	return math.Round(hours*100) / 100
+}
+
+func cliStatus(cCtx *cli.Context) error {
+	format, err := outputFormat(cCtx)
+	if err != nil {
+		return err
+	}
+	userConf, boardsConf, err := loadConf()
+	if err != nil {
+		return err
+	}
+	calendar, err := NewCalendar(userConf)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+	monday := today.AddDate(0, 0, -((int(today.Weekday()) + 6) % 7))
+	firstOfMonth := today.AddDate(0, 0, 1-today.Day())
+	// The current week can start in the previous month.
+	period := Period{From: firstOfMonth, To: today}
+	if monday.Before(firstOfMonth) {
+		period.From = monday
+	}
+
+	mondayAPIClient := NewMondayAPIClient(userConf, boardsConf, cCtx.Duration("timeout"))
+
+	months, err := fetchPeriodItems(cCtx, mondayAPIClient, boardsConf, period, 1)
+	if err != nil {
+		return err
+	}
+	loggedByDay, err := hoursByDay(months)
+	if err != nil {
+		return err
+	}
+
+	var weekLogged, monthLogged float64
+	for day, hours := range loggedByDay {
+		if !day.Before(monday) {
+			weekLogged += hours
+		}
+		if !day.Before(firstOfMonth) {
+			monthLogged += hours
+		}
+	}
+	year, week := today.ISOWeek()
+	weekTarget, weekExpected := calendar.WeekTarget(monday, today)
+	monthTarget, monthExpected := calendar.MonthTarget(firstOfMonth, today)
+	records := []StatusRecord{
+		newStatusRecord("day", today.Format(time.DateOnly), loggedByDay[today], calendar.DayTarget(today), calendar.DayTarget(today)),
+		newStatusRecord("week", fmt.Sprintf("%d-W%02d", year, week), weekLogged, weekExpected, weekTarget),
+		newStatusRecord("month", today.Format("2006-01"), monthLogged, monthExpected, monthTarget),
+	}
+	if err := printRecords(format, statusColumns, records); err != nil {
+		return err
+	}
+	if format != outputTable {
+		return nil
+	}
+
+	// Per-day progress of the month so far. Days off are only shown when something was logged.
+	fmt.Println()
+	table := tabby.New()
+	table.AddHeader("DAY", "LOGGED", "TARGET", "PROGRESS")
+	for day := firstOfMonth; !day.After(today); day = day.AddDate(0, 0, 1) {
+		logged, target := loggedByDay[day], calendar.DayTarget(day)
+		if logged == 0 && target == 0 {
+			continue
+		}
+		table.AddLine(day.Format("Mon 2006-01-02"), roundHundredths(logged), target, progressBar(logged, target))
+	}
+	table.Print()
+	return nil
+}
+
+// progressBar draws one character per half hour: logged hours as blocks, and the hours left to reach
+// the target as dots.
+func progressBar(logged, target float64) string {
+	filled := int(math.Round(logged * 2))
+	missing := int(math.Round(target*2)) - filled
+	if missing < 0 {
+		missing = 0
+	}
+	return strings.Repeat("█", filled) + strings.Repeat("·", missing)
+}
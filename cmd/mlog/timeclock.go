@@ -0,0 +1,147 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	// Example lines:
+	// i 2024-02-28 09:00:00 Project  description
+	// o 2024-02-28 12:30:00
+	regexTimeclockLine = regexp.MustCompile(`^([io])[[:blank:]]+([[:digit:]]{4}[-/.][[:digit:]]{2}[-/.][[:digit:]]{2})[[:blank:]]+([[:digit:]]{1,2}:[[:digit:]]{2}(?::[[:digit:]]{2})?)(?:[[:blank:]]+(.*))?$`)
+	// Separates the account from the description in a clock-in line.
+	regexTimeclockAccount = regexp.MustCompile(`^(.*?)(?:\t|[[:blank:]]{2,})(.*)$`)
+)
+
+// readTimeclockRows reads an hledger timeclock file directly, without needing hledger installed.
+// Each clock-in ("i") is paired with the following clock-out ("o"). Sessions crossing midnight are
+// split into per-day durations, and durations are summed per day and description. The
+// description is the clock-in description, or the account when there is none (like hledger
+// register shows it).
+//
+// When roundTo is positive, each day's total per description is rounded to the nearest multiple
+// of it. Otherwise, hours are kept to two decimals.
+func readTimeclockRows(r io.Reader, roundTo time.Duration) ([]InputRow, error) {
+	type session struct {
+		lineNumber  uint
+		start       time.Time
+		description string
+	}
+	type key struct {
+		day         string
+		description string
+	}
+	var rows []InputRow
+	rowIndexes := map[key]int{}
+	durationByRow := map[int]time.Duration{}
+	addDuration := func(lineNumber uint, day, description string, d time.Duration) {
+		index, ok := rowIndexes[key{day, description}]
+		if !ok {
+			index = len(rows)
+			rowIndexes[key{day, description}] = index
+			rows = append(rows, InputRow{LineNumber: lineNumber, DayYYYYMMDD: day, ItemName: description})
+		}
+		durationByRow[index] += d
+	}
+
+	var clockedIn *session
+	var lineNumber uint
+	scanner := bufio.NewScanner(r)
+	scanner.Split(bufio.ScanLines)
+	for scanner.Scan() {
+		lineNumber += 1
+		line := strings.TrimRight(scanner.Text(), " \t")
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") || strings.HasPrefix(line, "*") {
+			continue
+		}
+
+		matches := regexTimeclockLine.FindStringSubmatch(line)
+		if matches == nil {
+			fmt.Fprintf(infoWriter, "line %d: non-timeclock line ignored: %s\n", lineNumber, line)
+			continue
+		}
+		timestamp, err := parseTimeclockTimestamp(matches[2], matches[3])
+		if err != nil {
+			return nil, WrapWithStackF(err, "line %d: unable to parse date and time \"%s %s\". Exiting.", lineNumber, matches[2], matches[3])
+		}
+
+		if matches[1] == "i" {
+			if clockedIn != nil {
+				return nil, WithStackF("line %d: clock-in while the clock-in from line %d is still open. Exiting.", lineNumber, clockedIn.lineNumber)
+			}
+			description := strings.TrimSpace(matches[4])
+			if accountMatches := regexTimeclockAccount.FindStringSubmatch(description); accountMatches != nil {
+				if d := strings.TrimSpace(accountMatches[2]); d != "" {
+					description = d
+				} else {
+					description = strings.TrimSpace(accountMatches[1])
+				}
+			}
+			if description == "" {
+				return nil, WithStackF("line %d: clock-in without an account or description. Exiting.", lineNumber)
+			}
+			clockedIn = &session{lineNumber: lineNumber, start: timestamp, description: description}
+			continue
+		}
+
+		if clockedIn == nil {
+			return nil, WithStackF("line %d: clock-out without a matching clock-in. Exiting.", lineNumber)
+		}
+		if !timestamp.After(clockedIn.start) {
+			return nil, WithStackF("line %d: clock-out is not after the clock-in from line %d. Exiting.", lineNumber, clockedIn.lineNumber)
+		}
+		// Split the session at every midnight it crosses.
+		for start := clockedIn.start; start.Before(timestamp); {
+			year, month, day := start.Date()
+			end := time.Date(year, month, day+1, 0, 0, 0, 0, start.Location())
+			if end.After(timestamp) {
+				end = timestamp
+			}
+			addDuration(clockedIn.lineNumber, start.Format(time.DateOnly), clockedIn.description, end.Sub(start))
+			start = end
+		}
+		clockedIn = nil
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, WrapWithStack(err, "scanned stdin lines")
+	}
+	if clockedIn != nil {
+		return nil, WithStackF("line %d: clock-in without a clock-out. Exiting.", clockedIn.lineNumber)
+	}
+
+	result := make([]InputRow, 0, len(rows))
+	for i, row := range rows {
+		duration := durationByRow[i]
+		if roundTo > 0 {
+			duration = duration.Round(roundTo)
+		}
+		if duration <= 0 {
+			fmt.Fprintf(infoWriter, "line %d: %s, %s rounds down to 0 hours, ignored\n", row.LineNumber, row.DayYYYYMMDD, row.ItemName)
+			continue
+		}
+		row.Hours = strconv.FormatFloat(duration.Hours(), 'f', 2, 64)
+		fmt.Fprintf(infoWriter, "line %d: matched timeclock session: %s, %s, %s\n", row.LineNumber, row.DayYYYYMMDD, row.ItemName, row.Hours)
+		result = append(result, row)
+	}
+	return result, nil
+}
+
+// parseTimeclockTimestamp parses a timeclock date (yyyy-mm-dd, yyyy/mm/dd or yyyy.mm.dd) and time
+// (hh:mm or hh:mm:ss) in the local timezone.
+func parseTimeclockTimestamp(date, clock string) (time.Time, error) {
+	date = strings.NewReplacer("/", "-", ".", "-").Replace(date)
+	layout := "2006-01-02 15:04"
+	if strings.Count(clock, ":") == 2 {
+		layout = "2006-01-02 15:04:05"
+	}
+	if len(clock) == len("9:00") || len(clock) == len("9:00:00") {
+		clock = "0" + clock
+	}
+	return time.ParseInLocation(layout, date+" "+clock, time.Local)
+}
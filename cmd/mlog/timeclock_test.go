@@ -0,0 +1,86 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReadTimeclockRows(t *testing.T) {
+	discardInfo(t)
+	tests := []struct {
+		name    string
+		input   string
+		roundTo time.Duration
+		want    []InputRow
+		wantErr bool
+	}{
+		{
+			name: "sessions summed per day and description",
+			input: `i 2023-09-05 09:00:00 client:acme  Demo
+o 2023-09-05 10:30:00
+i 2023-09-05 13:00 client:acme  Demo
+o 2023-09-05 13:30
+i 2023/09/05 14:00 client:acme
+o 2023/09/05 15:00
+`,
+			want: []InputRow{
+				{LineNumber: 1, DayYYYYMMDD: "2023-09-05", ItemName: "Demo", Hours: "2.00"},
+				{LineNumber: 5, DayYYYYMMDD: "2023-09-05", ItemName: "client:acme", Hours: "1.00"},
+			},
+		},
+		{
+			name:  "session split at midnight",
+			input: "i 2023-09-05 22:30 ops  Release\no 2023-09-06 01:15\n",
+			want: []InputRow{
+				{LineNumber: 1, DayYYYYMMDD: "2023-09-05", ItemName: "Release", Hours: "1.50"},
+				{LineNumber: 1, DayYYYYMMDD: "2023-09-06", ItemName: "Release", Hours: "1.25"},
+			},
+		},
+		{
+			name:    "rounded",
+			input:   "i 2023-09-05 09:00 ops  Demo\no 2023-09-05 10:08\n",
+			roundTo: 15 * time.Minute,
+			want:    []InputRow{{LineNumber: 1, DayYYYYMMDD: "2023-09-05", ItemName: "Demo", Hours: "1.25"}},
+		},
+		{
+			name:    "rounded down to nothing",
+			input:   "i 2023-09-05 09:00 ops  Demo\no 2023-09-05 09:05\n",
+			roundTo: 15 * time.Minute,
+			want:    []InputRow{},
+		},
+		{
+			name:    "clock-out without clock-in",
+			input:   "o 2023-09-05 10:00\n",
+			wantErr: true,
+		},
+		{
+			name:    "clock-in still open",
+			input:   "i 2023-09-05 09:00 ops  Demo\ni 2023-09-05 10:00 ops  Demo\n",
+			wantErr: true,
+		},
+		{
+			name:    "missing clock-out",
+			input:   "i 2023-09-05 09:00 ops  Demo\n",
+			wantErr: true,
+		},
+		{
+			name:    "clock-out before clock-in",
+			input:   "i 2023-09-05 09:00 ops  Demo\no 2023-09-05 08:00\n",
+			wantErr: true,
+		},
+	}
+	for _, test := range tests {
+		got, err := readTimeclockRows(strings.NewReader(test.input), test.roundTo)
+		if test.wantErr {
+			if err == nil {
+				t.Errorf("%s: readTimeclockRows() = %+v, want an error", test.name, got)
+			}
+			continue
+		}
+		if err != nil || !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s: readTimeclockRows() = %+v, %v, want %+v", test.name, got, err, test.want)
+		}
+	}
+}
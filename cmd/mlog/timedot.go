@@ -0,0 +1,92 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	// Example lines:
+	// 2024-02-28
+	// * 2024/02/28 optional day description
+	regexTimedotDate = regexp.MustCompile(`^(?:\*+[[:blank:]]+)?([[:digit:]]{4})[-/.]([[:digit:]]{2})[-/.]([[:digit:]]{2})\b`)
+	// Example line:
+	// Release management  .... .... ....
+	regexTimedotEntry = regexp.MustCompile(`^[[:blank:]]*(.+?)(?:\t|[[:blank:]]{2,})[[:blank:]]*(.*?)[[:blank:]]*$`)
+)
+
+// readTimedotRows reads an hledger timedot file directly, without needing hledger installed.
+// Entries are grouped by date, and entries repeating a description on the same day are summed
+// into one row (reported with the line number of the first one), including when the date is listed
+// again further down.
+//
+// Supported quantities are the ones of hoursQuantity: dots (each dot is a quarter hour, spaces are
+// ignored), plain numbers (hours), numbers with "h" and/or "m" suffixes, and hh:mm. Lines starting
+// with "#", ";" or "*" (unless followed by a date) are comments, as is anything following ";" on an
+// entry line.
+func readTimedotRows(r io.Reader) ([]InputRow, error) {
+	type key struct {
+		day         string
+		description string
+	}
+	var rows []InputRow
+	rowIndexes := map[key]int{}
+	hoursByRow := map[int]float64{}
+	var currentDayYYYYMMDD string
+	var lineNumber uint
+	scanner := bufio.NewScanner(r)
+	scanner.Split(bufio.ScanLines)
+	for scanner.Scan() {
+		lineNumber += 1
+		line := scanner.Text()
+
+		if matches := regexTimedotDate.FindStringSubmatch(line); matches != nil {
+			currentDayYYYYMMDD = matches[1] + "-" + matches[2] + "-" + matches[3]
+			continue
+		}
+
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") || strings.HasPrefix(trimmed, ";") || strings.HasPrefix(trimmed, "*") {
+			continue
+		}
+		if i := strings.Index(line, ";"); i >= 0 {
+			line = line[:i]
+		}
+
+		matches := regexTimedotEntry.FindStringSubmatch(line)
+		if matches == nil || matches[2] == "" {
+			fmt.Fprintf(infoWriter, "line %d: entry without quantity ignored: %s\n", lineNumber, trimmed)
+			continue
+		}
+		if currentDayYYYYMMDD == "" {
+			return nil, WithStackF("line %d: timedot entry found before any date line. Exiting.", lineNumber)
+		}
+		description := matches[1]
+		hours, err := hoursQuantity(matches[2])
+		if err != nil {
+			return nil, WrapWithStackF(err, "line %d: quantity = %s: not a timedot quantity (dots, hours, 1h30m, 90m or 1:30). Exiting.",
+				lineNumber, matches[2])
+		}
+
+		index, ok := rowIndexes[key{currentDayYYYYMMDD, description}]
+		if !ok {
+			index = len(rows)
+			rowIndexes[key{currentDayYYYYMMDD, description}] = index
+			rows = append(rows, InputRow{LineNumber: lineNumber, DayYYYYMMDD: currentDayYYYYMMDD, ItemName: description})
+		}
+		hoursByRow[index] += hours
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, WrapWithStack(err, "scanned stdin lines")
+	}
+
+	for i := range rows {
+		rows[i].Hours = strconv.FormatFloat(hoursByRow[i], 'f', 2, 64)
+		fmt.Fprintf(infoWriter, "line %d: matched timedot entry: %s, %s, %s\n", rows[i].LineNumber, rows[i].DayYYYYMMDD, rows[i].ItemName, rows[i].Hours)
+	}
+	return rows, nil
+}
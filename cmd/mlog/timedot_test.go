@@ -0,0 +1,78 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestReadTimedotRows(t *testing.T) {
+	discardInfo(t)
+	tests := []struct {
+		name    string
+		input   string
+		want    []InputRow
+		wantErr bool
+	}{
+		{
+			name: "quantities",
+			input: `# comment
+2023-09-05
+Demo  .... ..
+Review  1h30m ; note
+* 2023/09/06 org-mode heading
+Demo    1:15
+Support  0.5
+`,
+			want: []InputRow{
+				{LineNumber: 3, DayYYYYMMDD: "2023-09-05", ItemName: "Demo", Hours: "1.50"},
+				{LineNumber: 4, DayYYYYMMDD: "2023-09-05", ItemName: "Review", Hours: "1.50"},
+				{LineNumber: 6, DayYYYYMMDD: "2023-09-06", ItemName: "Demo", Hours: "1.25"},
+				{LineNumber: 7, DayYYYYMMDD: "2023-09-06", ItemName: "Support", Hours: "0.50"},
+			},
+		},
+		{
+			name:  "repeated description summed",
+			input: "2023-09-05\nDemo  ..\nOther  1\nDemo  30m\n",
+			want: []InputRow{
+				{LineNumber: 2, DayYYYYMMDD: "2023-09-05", ItemName: "Demo", Hours: "1.00"},
+				{LineNumber: 3, DayYYYYMMDD: "2023-09-05", ItemName: "Other", Hours: "1.00"},
+			},
+		},
+		{
+			name:  "date listed twice",
+			input: "2023-09-05\nDemo  1\n2023-09-06\nDemo  2\n2023-09-05\nDemo  30m\n",
+			want: []InputRow{
+				{LineNumber: 2, DayYYYYMMDD: "2023-09-05", ItemName: "Demo", Hours: "1.50"},
+				{LineNumber: 4, DayYYYYMMDD: "2023-09-06", ItemName: "Demo", Hours: "2.00"},
+			},
+		},
+		{
+			name:  "entry without quantity ignored",
+			input: "2023-09-05\nDemo\n",
+			want:  nil,
+		},
+		{
+			name:    "entry before date",
+			input:   "Demo  ..\n",
+			wantErr: true,
+		},
+		{
+			name:    "invalid quantity",
+			input:   "2023-09-05\nDemo  1e2\n",
+			wantErr: true,
+		},
+	}
+	for _, test := range tests {
+		got, err := readTimedotRows(strings.NewReader(test.input))
+		if test.wantErr {
+			if err == nil {
+				t.Errorf("%s: readTimedotRows() = %+v, want an error", test.name, got)
+			}
+			continue
+		}
+		if err != nil || !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s: readTimedotRows() = %+v, %v, want %+v", test.name, got, err, test.want)
+		}
+	}
+}
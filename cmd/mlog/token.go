@@ -0,0 +1,109 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+
+	"github.com/go-errors/errors"
+	"github.com/zalando/go-keyring"
+)
+
+// keyringService is the service name of the access tokens stored in the OS keyring. The account is
+// named after the profile (see keyringAccount).
+const keyringService = "mlog"
+
+// keyringAccount returns the keyring account of the named profile's access token: "default" for the
+// top-level configuration, "profiles.<name>" otherwise. It doesn't depend on logging_user_id, which
+// can be a colleague's rather than the token owner's.
+func keyringAccount(name string) string {
+	if name == "" {
+		return "default"
+	}
+	return "profiles." + name
+}
+
+// apiAccessToken returns the access token from the first source that provides one, along with a
+// description of the source:
+//  1. the MLOG_API_TOKEN environment variable
+//  2. the output of api_access_token_command
+//  3. api_access_token (plaintext in config.toml)
+//  4. the OS keyring (Secret Service, macOS keychain or Windows credential manager), under the
+//     profile's account
+//
+// An empty token without error means that no source provides one.
+func (c *UserConf) apiAccessToken() (string, string, error) {
+	if token := strings.TrimSpace(os.Getenv("MLOG_API_TOKEN")); token != "" {
+		return token, "MLOG_API_TOKEN environment variable", nil
+	}
+
+	if c.APIAccessTokenCommand != "" {
+		token, err := runTokenCommand(c.APIAccessTokenCommand)
+		if err != nil {
+			return "", "", err
+		}
+		return token, "api_access_token_command", nil
+	}
+
+	if c.APIAccessToken != "" {
+		return c.APIAccessToken, "api_access_token", nil
+	}
+
+	token, err := keyring.Get(keyringService, keyringAccount(c.profile))
+	if err == nil {
+		return strings.TrimSpace(token), "OS keyring", nil
+	}
+	if !errors.Is(err, keyring.ErrNotFound) {
+		logger.Debugw("Keyring lookup failed", "error", err)
+	}
+	return "", "", nil
+}
+
+// tokenCommandOutputs keeps the token of every command already run, so that setup, which resolves
+// the token of each profile and again after configuring one, runs each command (and its prompt)
+// only once.
+var tokenCommandOutputs = map[string]string{}
+
+// runTokenCommand runs the command with the shell (cmd on Windows) and returns its trimmed output.
+// The command gets no stdin, which create-many reads its input from: helpers that prompt (e.g.
+// pinentry) use the terminal.
+func runTokenCommand(command string) (string, error) {
+	if token, ok := tokenCommandOutputs[command]; ok {
+		return token, nil
+	}
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.Command("cmd", "/C", command)
+	} else {
+		cmd = exec.Command("sh", "-c", command)
+	}
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if err != nil {
+		if output := strings.TrimSpace(stderr.String()); output != "" {
+			err = fmt.Errorf("%w: %s", err, output)
+		}
+		return "", WrapWithStackF(err, "api_access_token_command = %s: %v. Exiting.", command, err)
+	}
+	token := strings.TrimSpace(string(output))
+	if token == "" {
+		return "", WithStackF("api_access_token_command = %s: no output. Exiting.", command)
+	}
+	tokenCommandOutputs[command] = token
+	return token, nil
+}
+
+// storeKeyringToken saves the access token of the named profile in the OS keyring.
+func storeKeyringToken(name, token string) error {
+	account := keyringAccount(name)
+	err := keyring.Set(keyringService, account, token)
+	if err != nil {
+		return WrapWithStackF(err, "Unable to store the access token in the OS keyring: %v. Exiting.", err)
+	}
+	fmt.Printf("✅ Access token stored in the OS keyring (service %s, account %s)\n", keyringService, account)
+	return nil
+}
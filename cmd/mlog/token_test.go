@@ -0,0 +1,44 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/zalando/go-keyring"
+)
+
+func TestKeyringToken(t *testing.T) {
+	keyring.MockInit()
+	t.Setenv("MLOG_API_TOKEN", "")
+	userConf := &UserConf{
+		LoggingUserID: "123456789",
+		Profiles: map[string]*Profile{
+			// On behalf of a colleague: the token isn't theirs.
+			"colleague": {LoggingUserID: "987654321"},
+			"other":     {LoggingUserID: "123456789"},
+		},
+	}
+	for name, token := range map[string]string{"": "top-level token", "colleague": "colleague token"} {
+		if err := storeKeyringToken(name, token); err != nil {
+			t.Fatalf("storeKeyringToken(%q) error: %v", name, err)
+		}
+	}
+
+	tests := []struct {
+		profile string
+		want    string
+	}{
+		{profile: "", want: "top-level token"},
+		{profile: "colleague", want: "colleague token"},
+		{profile: "other", want: ""},
+	}
+	for _, test := range tests {
+		conf, err := userConf.withProfile(test.profile)
+		if err != nil {
+			t.Fatalf("withProfile(%q) error: %v", test.profile, err)
+		}
+		got, _, err := conf.apiAccessToken()
+		if err != nil || got != test.want {
+			t.Errorf("profile %q: apiAccessToken() = %q, %v, want %q", test.profile, got, err, test.want)
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"os"
+
+	"github.com/go-errors/errors"
+	"github.com/pelletier/go-toml/v2"
+)
+
+// UpdateMeta is kept next to the boards configuration file (boards.toml.meta), so that mlog update
+// only downloads it again once it changed.
+type UpdateMeta struct {
+	URL          string `toml:"url"`
+	ETag         string `toml:"etag,omitempty"`
+	LastModified string `toml:"last_modified,omitempty"`
+}
+
+// readUpdateMeta returns the validators of the last download of the boards configuration file from
+// url. They are ignored when the file is gone or was downloaded from elsewhere.
+func readUpdateMeta(boardsPath, url string) UpdateMeta {
+	var meta UpdateMeta
+	if _, err := os.Stat(boardsPath); err != nil {
+		return UpdateMeta{}
+	}
+	if err := loadTOML(boardsPath+".meta", &meta); err != nil || meta.URL != url {
+		return UpdateMeta{}
+	}
+	return meta
+}
+
+// writeUpdateMeta is best-effort: without it, the next update downloads the file again.
+func writeUpdateMeta(boardsPath string, meta UpdateMeta) {
+	content, err := toml.Marshal(meta)
+	if err == nil {
+		err = os.WriteFile(boardsPath+".meta", content, 0o644)
+	}
+	if err != nil {
+		logger.Debugw("Unable to save update metadata", "error", err)
+	}
+}
+
+// parseBoardsConf checks that downloaded content is a usable boards configuration before it
+// replaces the current one.
+func parseBoardsConf(content []byte) (*BoardsConf, error) {
+	var boardsConf BoardsConf
+	err := toml.Unmarshal(content, &boardsConf)
+	if err != nil {
+		return nil, err
+	}
+	if boardsConf.PersonColumnID == "" || boardsConf.HoursColumnID == "" {
+		return nil, errors.New("missing person_column_id or hours_column_id")
+	}
+	if len(boardsConf.Months) == 0 {
+		return nil, errors.New("no months")
+	}
+	for month, monthConf := range boardsConf.Months {
+		if monthConf == nil || monthConf.BoardID == "" {
+			return nil, fmt.Errorf("months.%s.board_id: missing", month)
+		}
+	}
+	return &boardsConf, nil
+}
+
+// replaceBoardsConf writes the content over the boards configuration file (see writeFileAtomic),
+// keeping the previous one as a backup (.bak). It returns false when the content is the same as the
+// current one, and whether there was a previous file to back up.
+func replaceBoardsConf(path string, content []byte) (bool, bool, error) {
+	current, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return false, false, WrapWithStackF(err, "Unable to read %s. Exiting.", path)
+	}
+	backedUp := err == nil
+	if backedUp && bytes.Equal(current, content) {
+		return false, false, nil
+	}
+	if backedUp {
+		err = os.WriteFile(path+".bak", current, 0o644)
+		if err != nil {
+			return false, false, WrapWithStackF(err, "Unable to back up %s. Exiting.", path)
+		}
+	}
+	err = writeFileAtomic(path, content, 0o644)
+	if err != nil {
+		return false, false, WrapWithStackF(err, "Unable to write %s. Exiting.", path)
+	}
+	return true, backedUp, nil
+}